@@ -0,0 +1,208 @@
+// Package appticket 离线解密并校验 Steam 加密应用票据(Encrypted App Ticket)。
+package appticket
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"github.com/bang-go/steam/steamid"
+	"google.golang.org/protobuf/proto"
+	"hash/crc32"
+	"net"
+	"time"
+)
+
+const (
+	TicketVersion = 1  //支持的外层票据版本
+	KeySize       = 32 //应用密钥长度(AES-256)，Steamworks 后台提供的是64位十六进制字符串
+)
+
+const (
+	ivRandomSize  = 3  //IV 末尾的随机字节数，其余部分为 HMAC
+	hmacKeySize   = 16 //HMAC 使用密钥的前16字节
+	hashSaltSize  = 8  //所有权票据后附带的加盐 SHA1 的盐长度
+	ownershipSize = 40 //所有权票据中许可证列表之前的固定长度
+)
+
+type Dlc struct {
+	AppId    uint32
+	Licenses []uint32
+}
+
+type Ticket struct {
+	Version        uint32 //所有权票据版本
+	SteamId        steamid.SteamID
+	AppId          uint32
+	ExternalIp     net.IP
+	InternalIp     net.IP
+	OwnershipFlags uint32
+	GeneratedAt    time.Time
+	ExpiresAt      time.Time
+	Licenses       []uint32
+	Dlc            []Dlc
+	UserData       []byte //客户端调用 RequestEncryptedAppTicket 时附带的数据
+}
+
+// IsExpired 票据是否已过期
+func (t *Ticket) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// Decrypt 使用应用密钥解密票据，校验 CRC、HMAC、哈希及有效期后返回票据内容
+func Decrypt(ticket []byte, key []byte) (t *Ticket, err error) {
+	if len(key) != KeySize {
+		err = fmt.Errorf("密钥长度异常,len:%d", len(key))
+		return
+	}
+	outer := &steampb.EncryptedAppTicket{}
+	if err = proto.Unmarshal(ticket, outer); err != nil {
+		return
+	}
+	if outer.GetTicketVersionNo() != TicketVersion {
+		err = fmt.Errorf("不支持的票据版本,version:%d", outer.GetTicketVersionNo())
+		return
+	}
+	if crc32.ChecksumIEEE(outer.GetEncryptedTicket()) != outer.GetCrcEncryptedticket() {
+		err = errors.New("票据CRC校验失败")
+		return
+	}
+	plain, err := symmetricDecrypt(outer.GetEncryptedTicket(), key)
+	if err != nil {
+		return
+	}
+	t, err = parseDecrypted(plain, int(outer.GetCbEncrypteduserdata()))
+	if err != nil {
+		return
+	}
+	if t.IsExpired() {
+		err = fmt.Errorf("票据已过期,expires:%s", t.ExpiresAt)
+	}
+	return
+}
+
+// IV 经 AES-ECB 加密后放在密文开头，明文经 AES-CBC 加密。
+// IV 的前13字节为 HMAC-SHA1(随机3字节 + 明文)，HMAC 密钥为应用密钥的前16字节。
+func symmetricDecrypt(data []byte, key []byte) (plain []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		err = fmt.Errorf("密文长度异常,len:%d", len(data))
+		return
+	}
+	iv := make([]byte, aes.BlockSize)
+	block.Decrypt(iv, data[:aes.BlockSize])
+	plain = make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data[aes.BlockSize:])
+	if plain, err = pkcs7Unpad(plain); err != nil {
+		return
+	}
+	mac := hmac.New(sha1.New, key[:hmacKeySize])
+	mac.Write(iv[aes.BlockSize-ivRandomSize:])
+	mac.Write(plain)
+	if !hmac.Equal(mac.Sum(nil)[:aes.BlockSize-ivRandomSize], iv[:aes.BlockSize-ivRandomSize]) {
+		err = errors.New("票据HMAC校验失败")
+	}
+	return
+}
+
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	n := int(data[len(data)-1])
+	if n == 0 || n > aes.BlockSize || n > len(data) || !bytes.Equal(data[len(data)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("票据填充异常")
+	}
+	return data[:len(data)-n], nil
+}
+
+// 解密后的内容: 用户数据 + 所有权票据(开头4字节为其长度) + 可选的加盐 SHA1(盐8字节 + 哈希20字节)
+func parseDecrypted(plain []byte, userDataSize int) (t *Ticket, err error) {
+	if userDataSize+4 > len(plain) {
+		err = fmt.Errorf("用户数据长度异常,len:%d", userDataSize)
+		return
+	}
+	ownershipLen := int(binary.LittleEndian.Uint32(plain[userDataSize:]))
+	end := userDataSize + ownershipLen
+	if ownershipLen < 4+ownershipSize || end > len(plain) {
+		err = fmt.Errorf("所有权票据长度异常,len:%d", ownershipLen)
+		return
+	}
+	if remainder := plain[end:]; len(remainder) >= hashSaltSize+sha1.Size {
+		h := sha1.New()
+		h.Write(plain[:end])
+		h.Write(remainder[:hashSaltSize])
+		if !bytes.Equal(h.Sum(nil), remainder[hashSaltSize:hashSaltSize+sha1.Size]) {
+			err = errors.New("票据哈希校验失败")
+			return
+		}
+	}
+	if t, err = parseOwnership(plain[userDataSize+4 : end]); err != nil {
+		return
+	}
+	t.UserData = plain[:userDataSize]
+	return
+}
+
+func parseOwnership(data []byte) (t *Ticket, err error) {
+	r := &reader{data: data}
+	t = &Ticket{}
+	t.Version = r.uint32()
+	rawSteamId := r.uint64()
+	t.AppId = r.uint32()
+	t.ExternalIp = r.ip()
+	t.InternalIp = r.ip()
+	t.OwnershipFlags = r.uint32()
+	t.GeneratedAt = time.Unix(int64(r.uint32()), 0)
+	t.ExpiresAt = time.Unix(int64(r.uint32()), 0)
+	t.Licenses = r.licenses()
+	dlcCount := int(r.uint16())
+	for i := 0; i < dlcCount && r.err == nil; i++ {
+		t.Dlc = append(t.Dlc, Dlc{AppId: r.uint32(), Licenses: r.licenses()})
+	}
+	if r.err != nil {
+		err = r.err
+		return
+	}
+	t.SteamId, err = steamid.New(fmt.Sprintf("%d", rawSteamId))
+	return
+}
+
+// 按小端序读取所有权票据，越界时记录错误
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = errors.New("所有权票据内容不完整")
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) uint16() uint16 { return binary.LittleEndian.Uint16(r.next(2)) }
+func (r *reader) uint32() uint32 { return binary.LittleEndian.Uint32(r.next(4)) }
+func (r *reader) uint64() uint64 { return binary.LittleEndian.Uint64(r.next(8)) }
+
+// IP 以小端序 uint32 存储
+func (r *reader) ip() net.IP {
+	v := r.uint32()
+	return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func (r *reader) licenses() (licenses []uint32) {
+	count := int(r.uint16())
+	for i := 0; i < count && r.err == nil; i++ {
+		licenses = append(licenses, r.uint32())
+	}
+	return
+}
@@ -0,0 +1,114 @@
+package appticket_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/bang-go/steam/appticket"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"google.golang.org/protobuf/proto"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+var testKey, _ = hex.DecodeString("ed9386073647cea58b7721490d59ed445723f0f66e7414e1533ba33cd803bdbd")
+
+// 用户 76561199181487706 拥有 480，附带 DLC 481，用户数据 "hello"，过期时间 2100-01-01
+const testVector = "080110f78bcb9702180520382a701841e78880ef35a3977ee80820d9c9f369c3" +
+	"f83808387607973ba7c4fc85743de5ff1cf976241319b3071f239323ab3948ca" +
+	"78004484de820bed87ce696e0cc8cdc402aaa7a5eab20e4f19e8a85e208f90be" +
+	"16fd923a841485aed4d923bb321ee5baa363bed19f3f992aabef4bb6664a"
+
+func TestDecrypt(t *testing.T) {
+	ticket, _ := hex.DecodeString(testVector)
+	got, err := appticket.Decrypt(ticket, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SteamId.RenderSteamID64() != 76561199181487706 || got.AppId != 480 || string(got.UserData) != "hello" {
+		t.Fatalf("unexpected ticket: %+v", got)
+	}
+	if len(got.Dlc) != 1 || got.Dlc[0].AppId != 481 || len(got.Licenses) != 1 || got.Licenses[0] != 1234 {
+		t.Fatalf("unexpected licenses: %+v %+v", got.Licenses, got.Dlc)
+	}
+	if got.ExternalIp.String() != "1.2.3.4" {
+		t.Fatalf("unexpected ip: %s", got.ExternalIp)
+	}
+
+	if built := buildTicket(t, []byte("hello"), 4102444800); !bytes.Equal(built, ticket) {
+		t.Fatalf("unexpected built ticket: %x", built)
+	}
+
+	tampered := bytes.Clone(ticket)
+	tampered[len(tampered)-1] ^= 1
+	if _, err = appticket.Decrypt(tampered, testKey); err == nil {
+		t.Fatal("expected tampered ticket to be rejected")
+	}
+	wrongKey := bytes.Clone(testKey)
+	wrongKey[0] ^= 1
+	if _, err = appticket.Decrypt(ticket, wrongKey); err == nil {
+		t.Fatal("expected wrong key to be rejected")
+	}
+	if _, err = appticket.Decrypt(buildTicket(t, nil, uint32(time.Now().Add(-time.Hour).Unix())), testKey); err == nil {
+		t.Fatal("expected expired ticket to be rejected")
+	}
+}
+
+// 按 Steam 的格式构造票据，随机部分使用固定值以保证结果可复现
+func buildTicket(t *testing.T, userData []byte, expires uint32) []byte {
+	var ownership bytes.Buffer
+	w := func(v any) { _ = binary.Write(&ownership, binary.LittleEndian, v) }
+	w(uint32(4))                 //version
+	w(uint64(76561199181487706)) //steamid
+	w(uint32(480))               //appid
+	w(uint32(0x01020304))        //external ip
+	w(uint32(0xc0a80001))        //internal ip
+	w(uint32(0))                 //flags
+	w(uint32(1700000000))        //generated
+	w(expires)
+	w(uint16(1))
+	w(uint32(1234))
+	w(uint16(1))
+	w(uint32(481))
+	w(uint16(0))
+	w(uint16(0)) //reserved
+
+	plain := append(bytes.Clone(userData), binary.LittleEndian.AppendUint32(nil, uint32(ownership.Len()+4))...)
+	plain = append(plain, ownership.Bytes()...)
+	salt := []byte("saltsalt")
+	sum := sha1.Sum(append(bytes.Clone(plain), salt...))
+	plain = append(append(plain, salt...), sum[:]...)
+
+	random := []byte{7, 8, 9}
+	mac := hmac.New(sha1.New, testKey[:16])
+	mac.Write(random)
+	mac.Write(plain)
+	iv := append(mac.Sum(nil)[:13], random...)
+	pad := aes.BlockSize - len(plain)%aes.BlockSize
+	plain = append(plain, bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	block, err := aes.NewCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := make([]byte, aes.BlockSize+len(plain))
+	block.Encrypt(encrypted, iv)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted[aes.BlockSize:], plain)
+
+	data, err := proto.Marshal(&steampb.EncryptedAppTicket{
+		TicketVersionNo:               proto.Uint32(1),
+		CrcEncryptedticket:            proto.Uint32(crc32.ChecksumIEEE(encrypted)),
+		CbEncrypteduserdata:           proto.Uint32(uint32(len(userData))),
+		CbEncryptedAppownershipticket: proto.Uint32(uint32(ownership.Len() + 4)),
+		EncryptedTicket:               encrypted,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
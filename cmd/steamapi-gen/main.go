@@ -0,0 +1,337 @@
+// steamapi-gen 根据 ISteamWebAPIUtil/GetSupportedAPIList 的响应内容生成 steamapi 的接口代码。
+//
+// 用法:
+//
+//	curl "https://api.steampowered.com/ISteamWebAPIUtil/GetSupportedAPIList/v1/?key=xxx" > apilist.json
+//	go run github.com/bang-go/steam/cmd/steamapi-gen -in apilist.json -out api_generated.go -interfaces ISteamUser,ISteamNews
+//
+// 生成的代码依赖 steamapi 包内的 Client、Params 等，只能输出到 steamapi 包；已有手写代码的接口(见 -exclude)默认跳过。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// steamapi 中已有手写代码的接口，默认不生成，避免类型重复声明
+const defaultExclude = "IGameServersService,IAuthenticationService,ISteamLeaderboards"
+
+// 不生成为字段的参数，由 steamapi.Client 统一处理
+var skipParams = map[string]bool{"key": true, "access_token": true, "input_json": true}
+
+// 常见单词的驼峰形式，保持与 steamapi 中 SteamId、AppId 的命名一致
+var knownWords = map[string]string{
+	"steamid":  "SteamId",
+	"steamids": "SteamIds",
+	"appid":    "AppId",
+	"appids":   "AppIds",
+	"url":      "Url",
+	"ip":       "Ip",
+	"ips":      "Ips",
+}
+
+// Steam 参数类型与 Go 类型的对应关系，未列出的类型按 string 处理
+var paramTypes = map[string]string{
+	"string":    "string",
+	"bool":      "bool",
+	"int32":     "int32",
+	"uint32":    "uint32",
+	"int64":     "int64",
+	"uint64":    "uint64",
+	"float":     "float32",
+	"double":    "float64",
+	"{enum}":    "int32",
+	"{message}": "any",
+	"rawbinary": "[]byte",
+}
+
+type apiList struct {
+	ApiList struct {
+		Interfaces []apiInterface `json:"interfaces"`
+	} `json:"apilist"`
+}
+
+type apiInterface struct {
+	Name    string      `json:"name"`
+	Methods []apiMethod `json:"methods"`
+}
+
+type apiMethod struct {
+	Name        string     `json:"name"`
+	Version     int        `json:"version"`
+	HttpMethod  string     `json:"httpmethod"`
+	Description string     `json:"description"`
+	Parameters  []apiParam `json:"parameters"`
+}
+
+type apiParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Optional    bool   `json:"optional"`
+	Description string `json:"description"`
+}
+
+type genInterface struct {
+	Name    string // ISteamUser
+	GoName  string // SteamUser
+	Methods []genMethod
+}
+
+type genMethod struct {
+	Name        string
+	Version     int
+	HttpMethod  string
+	Description string
+	ParamsType  string
+	Fields      []genField
+}
+
+type genField struct {
+	Name        string //参数名
+	GoName      string
+	GoType      string
+	Optional    bool
+	Description string
+}
+
+type options struct {
+	interfaces map[string]bool //为空时生成全部接口
+	exclude    map[string]bool //不生成的接口
+}
+
+func main() {
+	in := flag.String("in", "apilist.json", "GetSupportedAPIList 响应文件")
+	out := flag.String("out", "api_generated.go", "输出文件")
+	ifaces := flag.String("interfaces", "", "需要生成的接口，多个用逗号分隔，为空时生成全部")
+	exclude := flag.String("exclude", defaultExclude, "不生成的接口，多个用逗号分隔")
+	flag.Parse()
+
+	opts := &options{interfaces: splitNames(*ifaces), exclude: splitNames(*exclude)}
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(data, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func splitNames(s string) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func generate(data []byte, opts *options) (src []byte, err error) {
+	var list apiList
+	if err = json.Unmarshal(data, &list); err != nil {
+		return
+	}
+	var ifaces []genInterface
+	for _, iface := range list.ApiList.Interfaces {
+		if (len(opts.interfaces) > 0 && !opts.interfaces[iface.Name]) || opts.exclude[iface.Name] {
+			continue
+		}
+		ifaces = append(ifaces, buildInterface(iface))
+	}
+	if len(ifaces) == 0 {
+		err = fmt.Errorf("没有需要生成的接口")
+		return
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].Name < ifaces[j].Name })
+
+	var buf bytes.Buffer
+	if err = fileTemplate.Execute(&buf, ifaces); err != nil {
+		return
+	}
+	if src, err = format.Source(buf.Bytes()); err != nil {
+		err = fmt.Errorf("格式化生成代码失败: %w", err)
+	}
+	return
+}
+
+// 同名方法只保留最高版本
+func buildInterface(iface apiInterface) genInterface {
+	gi := genInterface{Name: iface.Name, GoName: interfaceGoName(iface.Name)}
+	latest := map[string]apiMethod{}
+	for _, m := range iface.Methods {
+		if old, ok := latest[m.Name]; !ok || m.Version > old.Version {
+			latest[m.Name] = m
+		}
+	}
+	for _, m := range latest {
+		gm := genMethod{
+			Name:        m.Name,
+			Version:     m.Version,
+			HttpMethod:  strings.ToUpper(m.HttpMethod),
+			Description: oneLine(m.Description),
+			ParamsType:  gi.GoName + m.Name + "Params",
+		}
+		used := map[string]bool{}
+		for _, p := range m.Parameters {
+			if skipParams[p.Name] {
+				continue
+			}
+			f := buildField(p)
+			//不同参数名转换后可能相同(如 steamid 与 steam_id)，重复时去掉末尾数字后从2开始追加序号
+			base := strings.TrimRight(f.GoName, "0123456789")
+			for n := 2; used[f.GoName]; n++ {
+				f.GoName = fmt.Sprintf("%s%d", base, n)
+			}
+			used[f.GoName] = true
+			gm.Fields = append(gm.Fields, f)
+		}
+		gi.Methods = append(gi.Methods, gm)
+	}
+	sort.Slice(gi.Methods, func(i, j int) bool { return gi.Methods[i].Name < gi.Methods[j].Name })
+	return gi
+}
+
+// 形如 name[0] 的参数为数组参数，生成切片字段
+func buildField(p apiParam) genField {
+	name := p.Name
+	goType, ok := paramTypes[p.Type]
+	if !ok {
+		goType = "string"
+	}
+	if strings.HasSuffix(name, "[0]") {
+		name = strings.TrimSuffix(name, "[0]")
+		goType = "[]" + goType
+	}
+	return genField{Name: name, GoName: camelCase(name), GoType: goType, Optional: p.Optional, Description: oneLine(p.Description)}
+}
+
+// ISteamUser -> SteamUser, IGameServersService -> GameServers
+func interfaceGoName(name string) string {
+	if len(name) > 1 && name[0] == 'I' && name[1] >= 'A' && name[1] <= 'Z' {
+		name = name[1:]
+	}
+	return strings.TrimSuffix(name, "Service")
+}
+
+// url_type -> UrlType, steamids -> SteamIds
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '.' || r == '-' }) {
+		if word, ok := knownWords[part]; ok {
+			b.WriteString(word)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"lower": func(s string) string {
+		return strings.ToLower(s[:1]) + s[1:]
+	},
+	"httpMethod": func(m string) string {
+		if m == "POST" {
+			return "http.MethodPost"
+		}
+		return "http.MethodGet"
+	},
+	"zeroCheck": func(f genField) string {
+		switch {
+		case strings.HasPrefix(f.GoType, "[]"):
+			return fmt.Sprintf("len(p.%s) > 0", f.GoName)
+		case f.GoType == "string":
+			return fmt.Sprintf("p.%s != \"\"", f.GoName)
+		case f.GoType == "bool":
+			return "p." + f.GoName
+		case f.GoType == "any":
+			return fmt.Sprintf("p.%s != nil", f.GoName)
+		default:
+			return fmt.Sprintf("p.%s != 0", f.GoName)
+		}
+	},
+}).Parse(`// Code generated by steamapi-gen. DO NOT EDIT.
+
+package steamapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+{{range $iface := .}}
+const (
+	Interface{{.GoName}} = "{{.Name}}"
+)
+{{range .Methods}}
+// {{.ParamsType}} {{$iface.Name}}/{{.Name}} 的请求参数
+type {{.ParamsType}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{.GoType}} {{if .Optional}}//可选{{if .Description}} {{end}}{{else if .Description}}//{{end}}{{.Description}}
+{{- end}}
+}
+
+func (p *{{.ParamsType}}) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+{{- range .Fields}}
+{{- if .Optional}}
+	if {{zeroCheck .}} {
+		params["{{.Name}}"] = p.{{.GoName}}
+	}
+{{- else}}
+	params["{{.Name}}"] = p.{{.GoName}}
+{{- end}}
+{{- end}}
+	return params
+}
+{{end}}
+// {{.GoName}}Service {{.Name}} 接口，响应内容去除 response 外层后解析到 out
+type {{.GoName}}Service interface {
+{{- range .Methods}}
+	{{.Name}}(ctx context.Context, params *{{.ParamsType}}, out any) error {{if .Description}}//{{.Description}}{{end}}
+{{- end}}
+}
+
+type {{.GoName}}ServiceConfig struct {
+	ApiKey  string
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
+}
+type {{lower .GoName}}ServiceEntity struct {
+	*{{.GoName}}ServiceConfig
+}
+
+func New{{.GoName}}Service(cfg *{{.GoName}}ServiceConfig) {{.GoName}}Service {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
+	return &{{lower .GoName}}ServiceEntity{ {{- .GoName}}ServiceConfig: cfg}
+}
+{{range .Methods}}
+func (s *{{lower $iface.GoName}}ServiceEntity) {{.Name}}(ctx context.Context, params *{{.ParamsType}}, out any) error {
+	return s.Client.Call(ctx, Interface{{$iface.GoName}}, "{{.Name}}", {{.Version}}, {{httpMethod .HttpMethod}}, params.params(), out)
+}
+{{end}}
+{{- end}}`))
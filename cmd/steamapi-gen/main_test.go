@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// 生成结果需要与 steamapi 中已提交的代码一致，修改生成器后需执行 go generate ./steamapi
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("../../steamapi/apilist.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(data, &options{exclude: splitNames(defaultExclude)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../steamapi/api_generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatal("steamapi/api_generated.go 已过期，请执行 go generate ./steamapi")
+	}
+	if _, err = generate(data, &options{interfaces: map[string]bool{"IUnknown": true}}); err == nil {
+		t.Fatal("expected error for unknown interface")
+	}
+}
+
+func TestGenerateExclude(t *testing.T) {
+	data := []byte(`{"apilist":{"interfaces":[
+		{"name":"IGameServersService","methods":[{"name":"GetAccountList","version":1,"httpmethod":"GET","parameters":[]}]},
+		{"name":"IPlayerService","methods":[{"name":"GetOwnedGames","version":1,"httpmethod":"GET","parameters":[
+			{"name":"steamid","type":"uint64"},{"name":"steam_id","type":"uint64","optional":true},{"name":"steam_id2","type":"uint64","optional":true}]}]}]}}`)
+	src, err := generate(data, &options{exclude: splitNames(defaultExclude)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//已有手写代码的接口不生成
+	if bytes.Contains(src, []byte("GameServers")) {
+		t.Fatal("expected IGameServersService to be excluded")
+	}
+	for _, field := range []string{"SteamId uint64", "SteamId2 uint64", "SteamId3 uint64"} {
+		if !strings.Contains(strings.Join(strings.Fields(string(src)), " "), field) {
+			t.Fatalf("missing field %q in:\n%s", field, src)
+		}
+	}
+	if src, err = generate(data, &options{}); err != nil || !bytes.Contains(src, []byte("NewGameServersService")) {
+		t.Fatalf("expected IGameServersService without exclude: %v", err)
+	}
+}
@@ -5,10 +5,21 @@ go 1.23.2
 require (
 	github.com/bang-go/network v0.0.3
 	github.com/bang-go/util v0.0.5
+	github.com/shopspring/decimal v1.4.0
+	go.opentelemetry.io/otel v1.35.0
+	go.opentelemetry.io/otel/metric v1.35.0
+	go.opentelemetry.io/otel/sdk v1.35.0
+	go.opentelemetry.io/otel/sdk/metric v1.35.0
+	go.opentelemetry.io/otel/trace v1.35.0
+	golang.org/x/sync v0.8.0
+	google.golang.org/protobuf v1.36.10
 )
 
 require (
 	github.com/bang-go/opt v0.0.2 // indirect
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/shopspring/decimal v1.4.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 )
@@ -0,0 +1,86 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const DefaultBroadcastParallelism = 8
+
+type BroadcastOptions struct {
+	Parallelism int           //同时执行的服务器数量，默认 DefaultBroadcastParallelism
+	Timeout     time.Duration //每个服务器的超时时间(包含等待连接、连接及认证)，0 表示不限制(仍受 ctx 控制)
+}
+
+// BroadcastResult 单个服务器的执行结果
+type BroadcastResult struct {
+	Addr    string
+	Output  []byte
+	Latency time.Duration
+	Err     error
+}
+
+// BroadcastResults 与传入的地址顺序一致
+type BroadcastResults []BroadcastResult
+
+// Failed 返回执行失败的结果
+func (r BroadcastResults) Failed() BroadcastResults {
+	var failed BroadcastResults
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err 合并所有失败服务器的错误，全部成功时返回 nil
+func (r BroadcastResults) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", result.Addr, result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// Broadcast 在多个服务器上执行同一条命令，单个服务器失败不影响其他服务器
+func (p *poolEntity) Broadcast(ctx context.Context, addrs []string, command []byte, opts *BroadcastOptions) BroadcastResults {
+	if opts == nil {
+		opts = &BroadcastOptions{}
+	}
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultBroadcastParallelism
+	}
+	results := make(BroadcastResults, len(addrs))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, addr := range addrs {
+		results[i].Addr = addr
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(result *BroadcastResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ctx := ctx
+			if opts.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			result.Output, result.Err = p.Exec(ctx, result.Addr, command)
+			result.Latency = time.Since(start)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
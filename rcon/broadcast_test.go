@@ -0,0 +1,49 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"github.com/bang-go/steam/rcon"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+	addr1, _, _ := echoServer(t, "123456", 0)
+	addr2, _, _ := echoServer(t, "123456", 0)
+	slow, _, _ := echoServer(t, "123456", 500*time.Millisecond)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := ln.Addr().String()
+	ln.Close()
+
+	pool := rcon.NewPool(&rcon.PoolConfig{Password: "123456", HealthCheckInterval: -1})
+	defer pool.Close()
+	addrs := []string{addr1, slow, down, addr2}
+	results := pool.Broadcast(context.Background(), addrs, []byte("say hi"), &rcon.BroadcastOptions{Parallelism: 2, Timeout: 100 * time.Millisecond})
+	if len(results) != len(addrs) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for i, result := range results {
+		if result.Addr != addrs[i] {
+			t.Fatalf("unexpected order: %+v", results)
+		}
+	}
+	for _, result := range []rcon.BroadcastResult{results[0], results[3]} {
+		if result.Err != nil || string(result.Output) != "say hi" || result.Latency <= 0 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	}
+	if !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", results[1].Err)
+	}
+	if results[2].Err == nil {
+		t.Fatal("expected dial error")
+	}
+	if failed := results.Failed(); len(failed) != 2 || results.Err() == nil {
+		t.Fatalf("unexpected failures: %+v", failed)
+	}
+}
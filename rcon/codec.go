@@ -0,0 +1,105 @@
+package rcon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrPacketSize packet 的 size 字段超出 [MinPacketSize, MaxPacketSize] 或与实际长度不符
+	ErrPacketSize = errors.New("异常的packet大小")
+)
+
+// MarshalBinary 按 Source RCON 格式编码 packet(size、id、type、body 及两个结尾的空字节)
+func (s *Packet) MarshalBinary() (data []byte, err error) {
+	if s.Size != int32(len(s.body))+MinPacketSize || s.Size > MaxPacketSize {
+		err = fmt.Errorf("%w,size:%d,body:%d", ErrPacketSize, s.Size, len(s.body))
+		return
+	}
+	data = make([]byte, 0, s.Size+PacketSizeSelfSize)
+	data = binary.LittleEndian.AppendUint32(data, uint32(s.Size))
+	data = binary.LittleEndian.AppendUint32(data, uint32(s.ID))
+	data = binary.LittleEndian.AppendUint32(data, uint32(s.Type))
+	data = append(data, s.body...)
+	data = append(data, 0, 0)
+	return
+}
+
+// UnmarshalBinary 解析一个完整的 packet，data 的长度必须与 size 字段一致
+func (s *Packet) UnmarshalBinary(data []byte) (err error) {
+	if len(data) < int(PacketSizeSelfSize) {
+		return fmt.Errorf("%w,len:%d", ErrPacketSize, len(data))
+	}
+	size := int32(binary.LittleEndian.Uint32(data))
+	if size < MinPacketSize || size > MaxPacketSize || int(size) != len(data)-int(PacketSizeSelfSize) {
+		return fmt.Errorf("%w,size:%d,len:%d", ErrPacketSize, size, len(data))
+	}
+	s.decodeFrame(size, data[PacketSizeSelfSize:])
+	return
+}
+
+// frame 为 size 字段之后的内容
+func (s *Packet) decodeFrame(size int32, frame []byte) {
+	s.Size = size
+	s.ID = int32(binary.LittleEndian.Uint32(frame[0:PacketIdSize]))
+	s.Type = PacketType(binary.LittleEndian.Uint32(frame[PacketIdSize : PacketIdSize+PacketTypeSize]))
+	s.body = frame[PacketIdSize+PacketTypeSize : size-PacketPaddingSize] //删除后两位last null terminated ascii
+}
+
+// Encoder 将 packet 编码后写入 io.Writer
+type Encoder struct {
+	w io.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode 编码多个 packet 并通过一次 Write 写入，任意 packet 不合法时不写入
+func (e *Encoder) Encode(packets ...*Packet) (err error) {
+	var data []byte
+	for _, p := range packets {
+		var frame []byte
+		if frame, err = p.MarshalBinary(); err != nil {
+			return
+		}
+		data = append(data, frame...)
+	}
+	_, err = e.w.Write(data)
+	return
+}
+
+// Decoder 从 io.Reader 中逐个读取 packet，内部带读缓冲，同一个数据流应始终使用同一个 Decoder
+type Decoder struct {
+	r *bufio.Reader
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReaderSize(r, int(MaxPacketSize+PacketSizeSelfSize))}
+}
+
+// Decode 读取下一个 packet，数据流在两个 packet 之间结束时返回 io.EOF，在 packet 中间结束时返回 io.ErrUnexpectedEOF
+func (d *Decoder) Decode() (p *Packet, err error) {
+	var sizeBuf [PacketSizeSelfSize]byte
+	if _, err = io.ReadFull(d.r, sizeBuf[:]); err != nil {
+		return
+	}
+	size := int32(binary.LittleEndian.Uint32(sizeBuf[:]))
+	if size < MinPacketSize || size > MaxPacketSize {
+		err = fmt.Errorf("%w,size:%d", ErrPacketSize, size)
+		return
+	}
+	frame := make([]byte, size)
+	if _, err = io.ReadFull(d.r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	p = &Packet{}
+	p.decodeFrame(size, frame)
+	return
+}
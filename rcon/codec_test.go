@@ -0,0 +1,93 @@
+package rcon_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/bang-go/steam/rcon"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	p := rcon.NewPacket(rcon.DataTypeExecCommand, 7, []byte("status"))
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, frame(7, rcon.DataTypeExecCommand, "status")) {
+		t.Fatalf("unexpected frame: %x", data)
+	}
+	var got rcon.Packet
+	if err = got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 7 || got.Type != rcon.DataTypeExecCommand || string(got.Body()) != "status" {
+		t.Fatalf("unexpected packet: %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err = rcon.NewEncoder(&buf).Encode(p, rcon.NewPacket(rcon.DataTypeResponseValue, 8, nil)); err != nil {
+		t.Fatal(err)
+	}
+	dec := rcon.NewDecoder(&buf)
+	for _, id := range []int32{7, 8} {
+		if p, err := dec.Decode(); err != nil || p.ID != id {
+			t.Fatalf("unexpected packet %+v: %v", p, err)
+		}
+	}
+	if _, err = dec.Decode(); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	large := &rcon.Packet{}
+	large.SetBody([]byte(strings.Repeat("x", int(rcon.MaxPacketSize))))
+	if _, err = large.MarshalBinary(); !errors.Is(err, rcon.ErrPacketSize) {
+		t.Fatalf("expected ErrPacketSize, got %v", err)
+	}
+	if _, err = rcon.NewDecoder(bytes.NewReader([]byte{0xff, 0xff, 0, 0})).Decode(); !errors.Is(err, rcon.ErrPacketSize) {
+		t.Fatalf("expected ErrPacketSize, got %v", err)
+	}
+	if _, err = rcon.NewDecoder(bytes.NewReader(data[:len(data)-1])).Decode(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	f.Add(frame(1, rcon.DataTypeAuth, "123456"))
+	f.Add(frame(-1, rcon.DataTypeAuthResponse, ""))
+	f.Add([]byte{10, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p rcon.Packet
+		if p.UnmarshalBinary(data) != nil {
+			return
+		}
+		out, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		//结尾的两个字节不参与解析，其余部分必须一致
+		if !bytes.Equal(out[:len(out)-2], data[:len(data)-2]) {
+			t.Fatalf("round trip mismatch: %x != %x", out, data)
+		}
+	})
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Add(append(frame(1, rcon.DataTypeResponseValue, "a"), frame(2, rcon.DataTypeResponseValue, "")...))
+	f.Add([]byte{0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		dec := rcon.NewDecoder(bytes.NewReader(data))
+		read := 0
+		for {
+			p, err := dec.Decode()
+			if err != nil {
+				return
+			}
+			read += int(p.Size) + int(rcon.PacketSizeSelfSize)
+			if read > len(data) || p.Size > rcon.MaxPacketSize || len(p.Body()) != int(p.Size-rcon.MinPacketSize) {
+				t.Fatalf("invalid packet: %+v", p)
+			}
+		}
+	})
+}
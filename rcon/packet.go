@@ -37,3 +37,9 @@ func NewPacket(packageType PacketType, packetId int32, body []byte) *Packet {
 func (s *Packet) Body() []byte {
 	return s.body
 }
+
+// SetBody 设置 body 并同步更新 Size
+func (s *Packet) SetBody(body []byte) {
+	s.body = body
+	s.Size = int32(len(body)) + MinPacketSize
+}
@@ -0,0 +1,232 @@
+package rcon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxConnsPerServer   = 2
+	DefaultIdleTimeout         = 5 * time.Minute
+	DefaultHealthCheckInterval = time.Minute
+	DefaultHealthCheckCommand  = "echo ping" //任意响应都说明连接可用，不支持 echo 的服务端返回未知命令也可以
+)
+
+// Pool 按服务器地址管理 RCON 连接，调用方无需关心连接的建立、认证及关闭
+type Pool interface {
+	// Exec 在指定服务器上执行命令，没有空闲连接时建立新连接并认证，连接数达到上限时等待
+	Exec(ctx context.Context, addr string, command []byte) ([]byte, error)
+	// Broadcast 并发在多个服务器上执行同一条命令，返回每个服务器的输出、耗时及错误
+	Broadcast(ctx context.Context, addrs []string, command []byte, opts *BroadcastOptions) BroadcastResults
+	Close()
+}
+
+type PoolConfig struct {
+	Config                                //连接配置模板，Addr 不使用
+	Password            string            //默认密码
+	Passwords           map[string]string //可选，按地址覆盖 Password
+	MaxConnsPerServer   int               //每个服务器的最大连接数，默认 DefaultMaxConnsPerServer
+	IdleTimeout         time.Duration     //空闲超过该时间的连接会被关闭，默认 DefaultIdleTimeout
+	HealthCheckInterval time.Duration     //空闲连接的检查及清理间隔，默认 DefaultHealthCheckInterval，小于0时不检查
+	HealthCheckCommand  string            //默认 DefaultHealthCheckCommand
+}
+
+type pooledConn struct {
+	rc       Rcon
+	lastUsed time.Time
+}
+
+type serverPool struct {
+	sem  chan struct{} //使用中的连接数，容量为 MaxConnsPerServer
+	idle []*pooledConn //最近使用的在后
+}
+
+type poolEntity struct {
+	*PoolConfig
+	mu      sync.Mutex
+	servers map[string]*serverPool
+	closed  bool
+	done    chan struct{}
+}
+
+func NewPool(cfg *PoolConfig) Pool {
+	if cfg.MaxConnsPerServer <= 0 {
+		cfg.MaxConnsPerServer = DefaultMaxConnsPerServer
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.HealthCheckInterval == 0 {
+		cfg.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if cfg.HealthCheckCommand == "" {
+		cfg.HealthCheckCommand = DefaultHealthCheckCommand
+	}
+	p := &poolEntity{PoolConfig: cfg, servers: map[string]*serverPool{}, done: make(chan struct{})}
+	if cfg.HealthCheckInterval > 0 {
+		go p.janitor()
+	}
+	return p
+}
+
+func (p *poolEntity) Exec(ctx context.Context, addr string, command []byte) (body []byte, err error) {
+	server, err := p.server(addr)
+	if err != nil {
+		return
+	}
+	select {
+	case server.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-server.sem }()
+	conn, reused, err := p.get(ctx, addr, server)
+	if err != nil {
+		return
+	}
+	body, err = conn.rc.ExecCommandContext(ctx, command)
+	//空闲连接已断开(如服务器重启)且命令未发出时，重新连接一次
+	if err != nil && reused && isUnsent(err) && ctx.Err() == nil {
+		conn.rc.Close()
+		if conn, err = p.dial(ctx, addr); err != nil {
+			return
+		}
+		body, err = conn.rc.ExecCommandContext(ctx, command)
+	}
+	//调用方 ctx 结束时连接仍可使用；其他错误时关闭连接，不放回空闲列表
+	if err != nil && ctx.Err() == nil {
+		conn.rc.Close()
+		return
+	}
+	conn.lastUsed = time.Now()
+	p.put(server, conn)
+	return
+}
+
+func (p *poolEntity) server(addr string) (*serverPool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrClosed
+	}
+	server, ok := p.servers[addr]
+	if !ok {
+		server = &serverPool{sem: make(chan struct{}, p.MaxConnsPerServer)}
+		p.servers[addr] = server
+	}
+	return server, nil
+}
+
+// 优先使用最近使用的空闲连接(reused 为 true)，没有时建立新连接并认证
+func (p *poolEntity) get(ctx context.Context, addr string, server *serverPool) (conn *pooledConn, reused bool, err error) {
+	p.mu.Lock()
+	if n := len(server.idle); n > 0 {
+		conn = server.idle[n-1]
+		server.idle = server.idle[:n-1]
+		p.mu.Unlock()
+		return conn, true, nil
+	}
+	p.mu.Unlock()
+	conn, err = p.dial(ctx, addr)
+	return
+}
+
+// 建立新连接并认证
+func (p *poolEntity) dial(ctx context.Context, addr string) (*pooledConn, error) {
+	cfg := p.Config
+	cfg.Addr = addr
+	rc := New(&cfg)
+	if err := rc.DialContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := rc.AuthContext(ctx, p.password(addr)); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return &pooledConn{rc: rc}, nil
+}
+
+func (p *poolEntity) put(server *serverPool, conn *pooledConn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		conn.rc.Close()
+		return
+	}
+	server.idle = append(server.idle, conn)
+}
+
+func (p *poolEntity) password(addr string) string {
+	if password, ok := p.Passwords[addr]; ok {
+		return password
+	}
+	return p.Password
+}
+
+// 定期关闭空闲过久的连接，并对其余空闲连接执行健康检查
+func (p *poolEntity) janitor() {
+	ticker := time.NewTicker(p.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+func (p *poolEntity) check() {
+	p.mu.Lock()
+	checks := map[*serverPool][]*pooledConn{}
+	for _, server := range p.servers {
+		checks[server] = server.idle
+		server.idle = nil
+	}
+	p.mu.Unlock()
+	for server, conns := range checks {
+		for _, conn := range conns {
+			if time.Since(conn.lastUsed) > p.IdleTimeout || !p.healthy(server, conn) {
+				conn.rc.Close()
+				continue
+			}
+			p.put(server, conn)
+		}
+	}
+}
+
+// 检查期间占用一个连接名额，名额已满说明服务器繁忙，跳过检查
+func (p *poolEntity) healthy(server *serverPool, conn *pooledConn) bool {
+	select {
+	case server.sem <- struct{}{}:
+	default:
+		return true
+	}
+	defer func() { <-server.sem }()
+	ctx := context.Background()
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+	_, err := conn.rc.ExecCommandContext(ctx, []byte(p.HealthCheckCommand)) //健康检查不刷新空闲时间
+	return err == nil
+}
+
+func (p *poolEntity) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	close(p.done)
+	for _, server := range p.servers {
+		for _, conn := range server.idle {
+			conn.rc.Close()
+		}
+		server.idle = nil
+	}
+}
@@ -0,0 +1,180 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/rcon"
+	"github.com/bang-go/steam/rcon/rcontest"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 启动回显服务器，返回地址及累计连接数、当前连接数
+func echoServer(t *testing.T, password string, delay time.Duration) (addr string, total, open *atomic.Int32) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	total, open = &atomic.Int32{}, &atomic.Int32{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			total.Add(1)
+			open.Add(1)
+			go func() {
+				defer open.Add(-1)
+				defer conn.Close()
+				for {
+					id, typ, body, err := readFrame(conn)
+					if err != nil {
+						return
+					}
+					switch typ {
+					case rcon.DataTypeAuth:
+						if body != password {
+							id = rcon.RespAuthFailed
+						}
+						_, _ = conn.Write(frame(id, rcon.DataTypeAuthResponse, ""))
+					case rcon.DataTypeExecCommand:
+						if body == "quit" {
+							return
+						}
+						time.Sleep(delay)
+						_, _ = conn.Write(frame(id, rcon.DataTypeResponseValue, body))
+					case rcon.DataTypeResponseValue:
+						_, _ = conn.Write(frame(id, rcon.DataTypeResponseValue, ""))
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String(), total, open
+}
+
+func TestPool(t *testing.T) {
+	addr1, total1, _ := echoServer(t, "123456", 10*time.Millisecond)
+	addr2, total2, _ := echoServer(t, "654321", 0)
+	pool := rcon.NewPool(&rcon.PoolConfig{
+		Config:              rcon.Config{Timeout: 5 * time.Second},
+		Password:            "123456",
+		Passwords:           map[string]string{addr2: "654321"},
+		MaxConnsPerServer:   2,
+		HealthCheckInterval: -1,
+	})
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			addr := addr1
+			if i%2 == 1 {
+				addr = addr2
+			}
+			cmd := fmt.Sprintf("echo %d", i)
+			data, err := pool.Exec(context.Background(), addr, []byte(cmd))
+			if err != nil || string(data) != cmd {
+				t.Errorf("unexpected response for %s: %q, %v", cmd, data, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if total1.Load() != 2 || total2.Load() < 1 || total2.Load() > 2 {
+		t.Fatalf("unexpected connections: %d, %d", total1.Load(), total2.Load())
+	}
+
+	//连接断开后不放回，下次调用重新连接
+	if _, err := pool.Exec(context.Background(), addr2, []byte("quit")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken, got %v", err)
+	}
+	before := total2.Load()
+	if data, err := pool.Exec(context.Background(), addr2, []byte("status")); err != nil || string(data) != "status" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+	if total2.Load() > before+1 {
+		t.Fatalf("unexpected connections: %d", total2.Load())
+	}
+
+	//连接数已满时等待，ctx 结束后返回
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = pool.Exec(ctx, addr1, []byte("status"))
+		}()
+	}
+	wg.Wait()
+	if total1.Load() != 2 {
+		t.Fatalf("expected at most 2 connections, got %d", total1.Load())
+	}
+
+	bad := rcon.NewPool(&rcon.PoolConfig{Password: "wrong", HealthCheckInterval: -1})
+	defer bad.Close()
+	if _, err := bad.Exec(context.Background(), addr1, []byte("status")); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+	pool.Close()
+	if _, err := pool.Exec(context.Background(), addr1, []byte("status")); !errors.Is(err, rcon.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestPoolIdle(t *testing.T) {
+	addr, total, open := echoServer(t, "123456", 0)
+	pool := rcon.NewPool(&rcon.PoolConfig{
+		Config:              rcon.Config{Timeout: time.Second},
+		Password:            "123456",
+		IdleTimeout:         50 * time.Millisecond,
+		HealthCheckInterval: 20 * time.Millisecond,
+	})
+	defer pool.Close()
+	if _, err := pool.Exec(context.Background(), addr, []byte("status")); err != nil {
+		t.Fatal(err)
+	}
+	//健康检查不刷新空闲时间，空闲超时后连接被关闭
+	deadline := time.Now().Add(2 * time.Second)
+	for open.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("idle connection not evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := pool.Exec(context.Background(), addr, []byte("status")); err != nil || total.Load() != 2 {
+		t.Fatalf("unexpected reconnect: %v, connections: %d", err, total.Load())
+	}
+}
+
+func TestPoolServerRestart(t *testing.T) {
+	srv := rcontest.NewServer(&rcontest.Config{Responses: map[string]string{"status": "ok"}})
+	defer srv.Close()
+	pool := rcon.NewPool(&rcon.PoolConfig{Config: rcon.Config{Timeout: time.Second}, Password: rcontest.DefaultPassword, HealthCheckInterval: -1})
+	defer pool.Close()
+	if data, err := pool.Exec(context.Background(), srv.Addr, []byte("status")); err != nil || string(data) != "ok" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+	//空闲连接被服务端断开后，命令未发出，重新连接后执行
+	srv.Disconnect()
+	time.Sleep(50 * time.Millisecond)
+	if data, err := pool.Exec(context.Background(), srv.Addr, []byte("status")); err != nil || string(data) != "ok" || srv.Accepted() != 2 {
+		t.Fatalf("unexpected response: %q, %v, accepted %d", data, err, srv.Accepted())
+	}
+	//其他错误时关闭连接，不放回空闲列表
+	if _, err := pool.Exec(context.Background(), srv.Addr, []byte(strings.Repeat("x", int(rcon.MaxPacketSize)))); !errors.Is(err, rcon.ErrPacketSize) {
+		t.Fatalf("expected ErrPacketSize, got %v", err)
+	}
+	if _, err := pool.Exec(context.Background(), srv.Addr, []byte("status")); err != nil || srv.Accepted() != 3 {
+		t.Fatalf("unexpected result: %v, accepted %d", err, srv.Accepted())
+	}
+}
@@ -1,12 +1,16 @@
 package rcon
 
 import (
-	"bufio"
 	"bytes"
-	"encoding/binary"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/bang-go/network/tcpx"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,168 +18,359 @@ const (
 	RespAuthFailed = -1 //认证失败
 )
 
+var (
+	ErrNotConnected = errors.New("未连接")
+	ErrNotAuthed    = errors.New("未认证")
+	ErrAuthFailed   = errors.New("认证失败") //密码校验失败
+	// ErrBroken 连接在读写过程中出错(如断开、写到一半、读到一半)，数据流已无法对齐，需要重新连接
+	ErrBroken = errors.New("连接已不可用")
+)
+
+// 请求未发出时的错误(如连接已断开)，重试不会导致命令重复执行
+type unsentError struct {
+	err error
+}
+
+func (e *unsentError) Error() string { return e.err.Error() }
+func (e *unsentError) Unwrap() error { return e.err }
+
+func isUnsent(err error) bool {
+	var unsent *unsentError
+	return errors.As(err, &unsent)
+}
+
+// 早于当前时间的截止时间，用于立即中断阻塞的写入
+var aLongTimeAgo = time.Unix(1, 0)
+
+// Rcon 并发安全，多个 goroutine 可以在同一个连接上同时执行命令，请求按顺序发出，响应按 packet ID 分发给对应的调用方
 type Rcon interface {
 	Dail() error
 	Auth(string) error
 	ExecCommand([]byte) ([]byte, error)
+	// DialContext 建立连接，ctx 控制连接超时
+	DialContext(ctx context.Context) error
+	// AuthContext 认证，ctx 及 Config.Timeout 控制本次调用的截止时间
+	AuthContext(ctx context.Context, password string) error
+	// ExecCommandContext 执行命令，ctx 及 Config.Timeout 控制本次调用的截止时间。
+	// 超时或取消时放弃本次调用，之后到达的响应会被丢弃；写入中断或读取出错时连接不可再使用
+	ExecCommandContext(ctx context.Context, command []byte) ([]byte, error)
 	Close()
 }
 type Config struct {
 	Addr    string
-	Timeout time.Duration
+	Timeout time.Duration //连接超时及每次调用的超时，0 表示不限制(仍受 ctx 控制)
+	//可选，设置后为连接、认证及每条命令记录 OpenTelemetry span 及指标，不记录密码和命令参数
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// 等待响应的调用
+type pendingCall struct {
+	commandID  int32
+	sentinelID int32
+	auth       bool         //认证请求，AUTH_RESPONSE 失败时 ID 为 -1，无法按 ID 分发
+	buf        bytes.Buffer //已收到的响应
+	done       chan callResult
+}
+
+type callResult struct {
+	packet *Packet //认证请求的 AUTH_RESPONSE
+	body   []byte
+	err    error
 }
 
 type rconEntity struct {
 	*Config
+	mu           sync.Mutex //保护以下连接状态
 	conn         tcpx.Connect
-	lastPacketID int32
-	isAuthed     bool
+	err          error                  //连接出现读写或帧格式错误后不可再使用
+	pending      map[int32]*pendingCall //命令ID及哨兵ID -> 调用
+	authCall     *pendingCall           //等待中的认证请求，同一时间只有一个
+	writeMu      sync.Mutex             //保证一次调用的多个packet连续写入
+	encoder      *Encoder
+	lastPacketID atomic.Int32
+	isAuthed     atomic.Bool
+	authMu       sync.Mutex //同一时间只允许一个认证请求
+	telemetry    *telemetry
 }
 
 func New(conf *Config) Rcon {
-	return &rconEntity{Config: conf}
+	return &rconEntity{Config: conf, telemetry: newTelemetry(conf.TracerProvider, conf.MeterProvider)}
 }
 
-func (s *rconEntity) Dail() (err error) {
-	client := tcpx.NewClient(&tcpx.ClientConfig{Addr: s.Addr, Timeout: s.Timeout})
-	s.conn, err = client.Dail()
-	return
+func (s *rconEntity) Dail() error {
+	return s.DialContext(context.Background())
+}
+
+func (s *rconEntity) Auth(password string) error {
+	return s.AuthContext(context.Background(), password)
 }
 
-func (s *rconEntity) Auth(password string) (err error) {
-	s.lastPacketID++
-	packet := NewPacket(DataTypeAuth, s.lastPacketID, []byte(password))
-	err = s.writePacket(packet)
+func (s *rconEntity) ExecCommand(command []byte) ([]byte, error) {
+	return s.ExecCommandContext(context.Background(), command)
+}
+
+// DialContext 建立连接并启动读取循环，已有连接时关闭旧连接
+func (s *rconEntity) DialContext(ctx context.Context) (err error) {
+	op := s.telemetry.start(ctx, s.Addr, "dial")
+	defer func() { op.end(0, 0, err) }()
+	dialer := &net.Dialer{Timeout: s.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.Addr)
 	if err != nil {
 		return
 	}
-	respPacket, err := s.readPacket()
+	s.Close()
+	conn := tcpx.NewConnect(netConn) //不设置 tcpx 的超时，由每次调用自行设置截止时间
+	s.writeMu.Lock()
+	s.encoder = NewEncoder(connWriter{conn: conn})
+	s.writeMu.Unlock()
+	s.mu.Lock()
+	s.conn = conn
+	s.err = nil
+	s.pending = map[int32]*pendingCall{}
+	s.authCall = nil
+	s.mu.Unlock()
+	s.isAuthed.Store(false)
+	go s.readLoop(conn, NewDecoder(netConn))
+	return
+}
+
+func (s *rconEntity) AuthContext(ctx context.Context, password string) (err error) {
+	op := s.telemetry.start(ctx, s.Addr, "auth")
+	defer func() { op.end(0, 0, err) }()
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	call := &pendingCall{commandID: s.nextID(), auth: true, done: make(chan callResult, 1)}
+	result, err := s.call(ctx, call, NewPacket(DataTypeAuth, call.commandID, []byte(password)))
 	if err != nil {
 		return
 	}
-	return s.isAuthSuccess(respPacket)
+	return s.isAuthSuccess(result.packet)
 }
 
-// ExecCommand 执行命令
-func (s *rconEntity) ExecCommand(command []byte) (body []byte, err error) {
-	if s.isAuthed == false {
-		err = errors.New("未认证")
+// ExecCommandContext 执行命令，响应超过单个包大小时会被拆分为多个包，此处会合并为完整的响应
+func (s *rconEntity) ExecCommandContext(ctx context.Context, command []byte) (body []byte, err error) {
+	op := s.telemetry.start(ctx, s.Addr, "exec", AttrCommand.String(commandName(command)))
+	defer func() { op.end(len(command), len(body), err) }()
+	//连接已断开时返回 ErrBroken，以便调用方判断需要重新连接
+	if err = s.connErr(); err != nil {
+		err = &unsentError{err}
 		return
 	}
-	//发送命令
-	s.lastPacketID++
-	packet := NewPacket(DataTypeExecCommand, s.lastPacketID, command)
-	err = s.writePacket(packet)
-	if err != nil {
-		return
-	}
-	//发送空包 为了循环接受响应直到空的响应为止 抓包发现不需要
-	//err = s.writePacket(s.getCommandEmptyPackage())
-	//if err != nil {
-	//	return
-	//}
-	respPacket, err := s.readPacket()
-	if err != nil {
+	if !s.isAuthed.Load() {
+		err = ErrNotAuthed
 		return
 	}
-	err = s.checkID(respPacket)
+	//命令后紧接着发送一个空的 RESPONSE_VALUE 包作为哨兵，服务端按顺序处理，收到哨兵的回包时说明命令的响应已全部接收
+	call := &pendingCall{commandID: s.nextID(), sentinelID: s.nextID(), done: make(chan callResult, 1)}
+	result, err := s.call(ctx, call, NewPacket(DataTypeExecCommand, call.commandID, command), NewPacket(DataTypeResponseValue, call.sentinelID, nil))
 	if err != nil {
 		return
 	}
-	body = respPacket.Body()
+	body = result.body
 	return
 }
 
-func (s *rconEntity) isAuthSuccess(packet *Packet) (err error) {
-	if packet.Type != DataTypeAuthResponse {
-		err = errors.New(fmt.Sprintf("不匹配的type类型,type:%d", packet.Type))
+// 注册调用并发送请求，等待读取循环返回响应，超时或取消时注销调用
+func (s *rconEntity) call(ctx context.Context, call *pendingCall, packets ...*Packet) (result callResult, err error) {
+	if err = ctx.Err(); err != nil {
 		return
 	}
-	if packet.ID == RespAuthFailed { //认证失败，密码校验失败
-		err = errors.New(fmt.Sprintf("认证失败"))
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	if err = s.register(call); err != nil {
+		err = &unsentError{err}
 		return
 	}
-	if packet.ID > RespAuthFailed {
-		s.isAuthed = true
+	if err = s.writePackets(ctx, packets...); err != nil {
+		s.unregister(call)
 		return
 	}
+	select {
+	case result = <-call.done:
+		err = result.err
+	case <-ctx.Done():
+		s.unregister(call)
+		err = ctx.Err()
+	}
 	return
 }
 
-// 通过tcp发送packet
-func (s *rconEntity) writePacket(packet *Packet) (err error) {
-	data, err := s.encodePacket(packet)
-	if err != nil {
-		return
+// 返回连接出错后记录的错误，未连接时返回 nil
+func (s *rconEntity) connErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
 	}
-	err = s.conn.Send(data)
-	return
+	return s.err
 }
 
-// 通过tcp连接读取packet
-func (s *rconEntity) readPacket() (p *Packet, err error) {
-	//input io.Reader
-	reader := bufio.NewReader(s.conn.Conn())
-	p = &Packet{}
-	err = binary.Read(reader, binary.LittleEndian, &p.Size)
-	if err != nil {
-		return
+func (s *rconEntity) register(call *pendingCall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return ErrNotConnected
+	}
+	if s.err != nil {
+		return s.err
 	}
-	if p.Size < MinPacketSize {
-		err = fmt.Errorf("size小于最小值,size:%d", p.Size)
+	if call.auth {
+		s.authCall = call
+		return nil
+	}
+	s.pending[call.commandID] = call
+	s.pending[call.sentinelID] = call
+	return nil
+}
+
+func (s *rconEntity) unregister(call *pendingCall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if call.auth {
+		if s.authCall == call {
+			s.authCall = nil
+		}
 		return
 	}
+	delete(s.pending, call.commandID)
+	delete(s.pending, call.sentinelID)
+}
 
-	err = binary.Read(reader, binary.LittleEndian, &p.ID)
-	if err != nil {
+// 生成下一个 packet ID，始终为正数，避免与 RespAuthFailed 冲突
+func (s *rconEntity) nextID() int32 {
+	for {
+		id := s.lastPacketID.Add(1)
+		if id > 0 {
+			return id
+		}
+		s.lastPacketID.CompareAndSwap(id, 0) //溢出后从1重新开始
+	}
+}
+
+// 持续读取响应并按 packet ID 分发，读取出错时连接不可再使用，所有等待中的调用返回错误
+func (s *rconEntity) readLoop(conn tcpx.Connect, decoder *Decoder) {
+	for {
+		p, err := decoder.Decode()
+		if err != nil {
+			s.fail(conn, fmt.Errorf("%w, 读取packet失败: %w", ErrBroken, err))
+			return
+		}
+		s.dispatch(p)
+	}
+}
+
+func (s *rconEntity) dispatch(p *Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	//srcds 在 AUTH_RESPONSE 之前会先返回一个空的 RESPONSE_VALUE，只处理 AUTH_RESPONSE
+	if call := s.authCall; call != nil && p.Type == DataTypeAuthResponse && (p.ID == call.commandID || p.ID == RespAuthFailed) {
+		s.authCall = nil
+		call.done <- callResult{packet: p}
 		return
 	}
-	err = binary.Read(reader, binary.LittleEndian, &p.Type)
-	if err != nil {
+	//其他ID为已放弃的调用或之前命令遗留的包(如 srcds 在哨兵回包后追加的 0x01 包)，直接丢弃
+	call, ok := s.pending[p.ID]
+	if !ok {
 		return
 	}
+	switch p.ID {
+	case call.commandID:
+		call.buf.Write(p.Body())
+	case call.sentinelID:
+		delete(s.pending, call.commandID)
+		delete(s.pending, call.sentinelID)
+		call.done <- callResult{body: call.buf.Bytes()}
+	}
+}
 
-	bodyLen := p.Size - (PacketIdSize + PacketTypeSize)
-	bodyBuf := make([]byte, bodyLen)
-	err = binary.Read(reader, binary.LittleEndian, &bodyBuf)
-	if err != nil {
+// 标记连接不可用，关闭连接并通知所有等待中的调用，conn 已被替换时忽略
+func (s *rconEntity) fail(conn tcpx.Connect, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != conn {
 		return
 	}
-	if len(bodyBuf) < int(PacketPaddingSize) {
-		err = fmt.Errorf("异常的body大小, lne:%d", len(bodyBuf))
+	if s.err == nil {
+		s.err = err
 	}
-	p.body = bodyBuf[:bodyLen-PacketPaddingSize] //删除后两位last null terminated ascii
-	return
+	s.conn.Close()
+	for id, call := range s.pending {
+		if id == call.commandID {
+			call.done <- callResult{err: s.err}
+		}
+	}
+	s.pending = map[int32]*pendingCall{}
+	if s.authCall != nil {
+		s.authCall.done <- callResult{err: s.err}
+		s.authCall = nil
+	}
+	s.isAuthed.Store(false)
 }
 
-func (s *rconEntity) encodePacket(p *Packet) (data []byte, err error) {
-	var buf bytes.Buffer
-	_ = binary.Write(&buf, binary.LittleEndian, p.Size)
-	_ = binary.Write(&buf, binary.LittleEndian, p.ID)
-	_ = binary.Write(&buf, binary.LittleEndian, p.Type)
-	_ = binary.Write(&buf, binary.LittleEndian, p.body)
-	_ = binary.Write(&buf, binary.LittleEndian, [2]byte{})
-	if buf.Len() > int(MaxPacketSize) {
-		err = fmt.Errorf("packet长度超出最大限制,len:%d", buf.Len())
+func (s *rconEntity) isAuthSuccess(packet *Packet) (err error) {
+	if packet.Type != DataTypeAuthResponse {
+		err = errors.New(fmt.Sprintf("不匹配的type类型,type:%d", packet.Type))
+		return
+	}
+	if packet.ID == RespAuthFailed { //认证失败，密码校验失败
+		err = ErrAuthFailed
+		return
+	}
+	if packet.ID > RespAuthFailed {
+		s.isAuthed.Store(true)
 		return
 	}
-	data = buf.Bytes()
 	return
 }
 
-//	func (s *rconEntity) getCommandEmptyPackage() *Packet {
-//		return NewPacket(DataTypeResponseValue, s.lastPacketID, "")
-//	}
-//
-// 检测请求包是否和请求包匹配
-func (s *rconEntity) checkID(p *Packet) (err error) {
-	if p.ID != s.lastPacketID {
-		err = fmt.Errorf("packet包中ID不匹配: origin_id:%d,current_id:%d", s.lastPacketID, p.ID)
+// 将多个packet编码后一次写入连接，packet 不合法时不会写入任何数据，写入中断时连接不可再使用
+func (s *rconEntity) writePackets(ctx context.Context, packets ...*Packet) (err error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	conn, connErr := s.conn, s.err
+	s.mu.Unlock()
+	if connErr != nil {
+		return &unsentError{connErr}
+	}
+	netConn := conn.Conn()
+	deadline, _ := ctx.Deadline()
+	if err = netConn.SetWriteDeadline(deadline); err != nil {
 		return
 	}
+	stop := context.AfterFunc(ctx, func() { _ = netConn.SetWriteDeadline(aLongTimeAgo) })
+	defer stop()
+	if err = s.encoder.Encode(packets...); err != nil && !errors.Is(err, ErrPacketSize) {
+		err = fmt.Errorf("%w, 写入packet失败: %w", ErrBroken, err)
+		if ctx.Err() != nil {
+			err = fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+		s.fail(conn, err)
+	}
 	return
 }
 
+// 将 tcpx.Connect 包装为 io.Writer 供 Encoder 使用
+type connWriter struct {
+	conn tcpx.Connect
+}
+
+func (w connWriter) Write(p []byte) (int, error) {
+	if err := w.conn.Send(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 func (s *rconEntity) Close() {
-	s.conn.Close()
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn != nil {
+		s.fail(conn, fmt.Errorf("%w, 连接已关闭", ErrBroken))
+	}
 }
@@ -1,27 +1,323 @@
 package rcon_test
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"github.com/bang-go/steam/rcon"
-	"log"
+	"github.com/bang-go/steam/rcon/rcontest"
+	"io"
+	"net"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestRcon(t *testing.T) {
+	srv := rcontest.NewServer(&rcontest.Config{
+		Responses:               map[string]string{"bot_add_ct": "L 10/19/2026 - 12:00:00: \"Bot<2><BOT><>\" joined team \"CT\"\n"},
+		EmptyResponseBeforeAuth: true,
+		SentinelTrailer:         true,
+	})
+	defer srv.Close()
 	var err error
-	rc := rcon.New(&rcon.Config{Addr: "127.0.0.1:27015", Timeout: time.Second * 5})
+	rc := rcon.New(&rcon.Config{Addr: srv.Addr, Timeout: time.Second * 5})
 	err = rc.Dail()
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 	defer rc.Close()
 	err = rc.Auth("123456")
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 	data, err := rc.ExecCommand([]byte("bot_add_ct"))
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
-	log.Println(data)
+	t.Log(string(data))
+}
+
+// 按 Source RCON 格式编码一个包
+func frame(id int32, typ rcon.PacketType, body string) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+int(rcon.MinPacketSize)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(id))
+	data = binary.LittleEndian.AppendUint32(data, uint32(typ))
+	return append(append(data, body...), 0, 0)
+}
+
+// 读取一个包，返回ID、类型及body
+func readFrame(r io.Reader) (id int32, typ rcon.PacketType, body string, err error) {
+	var size int32
+	if err = binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return
+	}
+	buf := make([]byte, size)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return
+	}
+	return int32(binary.LittleEndian.Uint32(buf)), rcon.PacketType(binary.LittleEndian.Uint32(buf[4:])), string(buf[8 : size-2]), nil
+}
+
+func TestExecCommand(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	long := strings.Repeat("x", 5000)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			id, typ, body, err := readFrame(conn)
+			if err != nil {
+				return
+			}
+			switch typ {
+			case rcon.DataTypeAuth:
+				//与 srcds 一致，先返回空的 RESPONSE_VALUE 再返回 AUTH_RESPONSE，且一次写入
+				_, _ = conn.Write(append(frame(id, rcon.DataTypeResponseValue, ""), frame(id, rcon.DataTypeAuthResponse, "")...))
+			case rcon.DataTypeExecCommand:
+				out := body + ":" + long
+				var data []byte
+				for len(out) > 0 {
+					n := min(len(out), 4000)
+					data = append(data, frame(id, rcon.DataTypeResponseValue, out[:n])...)
+					out = out[n:]
+				}
+				_, _ = conn.Write(data)
+			case rcon.DataTypeResponseValue: //哨兵的回包及 srcds 追加的包
+				_, _ = conn.Write(append(frame(id, rcon.DataTypeResponseValue, ""), frame(id, rcon.DataTypeResponseValue, "\x00\x00\x00\x01\x00\x00\x00\x00")...))
+			}
+		}
+	}()
+
+	rc := rcon.New(&rcon.Config{Addr: ln.Addr().String(), Timeout: time.Second})
+	if err = rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err = rc.Auth("123456"); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"cvarlist", "status"} {
+		data, err := rc.ExecCommand([]byte(cmd))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != cmd+":"+long {
+			t.Fatalf("unexpected response for %s: %d bytes", cmd, len(data))
+		}
+	}
+}
+
+// 响应拆分为多个包，且包边界与 TCP 分段不一致(一次读取包含多个包或半个包)
+func TestExecCommandFragmented(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	out := strings.Repeat("0123456789", 100)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var pending []byte
+		for {
+			id, typ, body, err := readFrame(conn)
+			if err != nil {
+				return
+			}
+			switch typ {
+			case rcon.DataTypeAuth:
+				_, _ = conn.Write(frame(id, rcon.DataTypeAuthResponse, ""))
+			case rcon.DataTypeExecCommand:
+				for i := 0; i < len(out); i += 100 {
+					pending = append(pending, frame(id, rcon.DataTypeResponseValue, body+out[i:i+100])...)
+				}
+			case rcon.DataTypeResponseValue:
+				pending = append(pending, frame(id, rcon.DataTypeResponseValue, "")...)
+				for len(pending) > 0 {
+					n := min(len(pending), 7)
+					_, _ = conn.Write(pending[:n])
+					pending = pending[n:]
+					time.Sleep(time.Millisecond / 10)
+				}
+			}
+		}
+	}()
+
+	rc := rcon.New(&rcon.Config{Addr: ln.Addr().String(), Timeout: 5 * time.Second})
+	if err = rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err = rc.Auth("123456"); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"a", "b"} {
+		data, err := rc.ExecCommand([]byte(cmd))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want strings.Builder
+		for i := 0; i < len(out); i += 100 {
+			want.WriteString(cmd + out[i:i+100])
+		}
+		if string(data) != want.String() {
+			t.Fatalf("unexpected response for %s: %d bytes", cmd, len(data))
+		}
+	}
+}
+
+func TestExecCommandContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var mu sync.Mutex
+		write := func(data []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = conn.Write(data)
+		}
+		slow := false
+		for {
+			id, typ, body, err := readFrame(conn)
+			if err != nil {
+				return
+			}
+			switch typ {
+			case rcon.DataTypeAuth:
+				write(frame(id, rcon.DataTypeAuthResponse, ""))
+			case rcon.DataTypeExecCommand:
+				if body == "quit" {
+					return
+				}
+				slow = body == "slow"
+				write(frame(id, rcon.DataTypeResponseValue, body))
+			case rcon.DataTypeResponseValue:
+				if slow { //慢命令的哨兵回包延迟返回
+					time.AfterFunc(200*time.Millisecond, func() { write(frame(id, rcon.DataTypeResponseValue, "")) })
+					continue
+				}
+				write(frame(id, rcon.DataTypeResponseValue, ""))
+			}
+		}
+	}()
+
+	rc := rcon.New(&rcon.Config{Addr: ln.Addr().String(), Timeout: time.Second})
+	if _, err = rc.ExecCommand([]byte("status")); !errors.Is(err, rcon.ErrNotAuthed) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = rc.AuthContext(context.Background(), "123456"); !errors.Is(err, rcon.ErrNotConnected) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = rc.DialContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err = rc.AuthContext(context.Background(), "123456"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err = rc.ExecCommandContext(ctx, []byte("slow")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Fatal("expected call to be aborted by ctx")
+	}
+	//放弃的调用之后到达的响应不会影响后续命令
+	data, err := rc.ExecCommandContext(context.Background(), []byte("fast"))
+	if err != nil || string(data) != "fast" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+	time.Sleep(250 * time.Millisecond)
+	if data, err = rc.ExecCommandContext(context.Background(), []byte("fast")); err != nil || string(data) != "fast" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+
+	//服务端断开后连接不可再使用
+	if _, err = rc.ExecCommandContext(context.Background(), []byte("quit")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected broken connection, got %v", err)
+	}
+	if _, err = rc.ExecCommandContext(context.Background(), []byte("fast")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected broken connection, got %v", err)
+	}
+
+	//已取消的 ctx 不发出任何请求
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err = rcon.New(&rcon.Config{Addr: ln.Addr().String()}).DialContext(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
+
+func TestConcurrentExecCommand(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			id, typ, body, err := readFrame(conn)
+			if err != nil {
+				return
+			}
+			switch typ {
+			case rcon.DataTypeAuth:
+				_, _ = conn.Write(append(frame(id, rcon.DataTypeResponseValue, ""), frame(id, rcon.DataTypeAuthResponse, "")...))
+			case rcon.DataTypeExecCommand:
+				//拆分为两个包返回
+				_, _ = conn.Write(append(frame(id, rcon.DataTypeResponseValue, body[:1]), frame(id, rcon.DataTypeResponseValue, body[1:])...))
+			case rcon.DataTypeResponseValue:
+				_, _ = conn.Write(frame(id, rcon.DataTypeResponseValue, ""))
+			}
+		}
+	}()
+	rc := rcon.New(&rcon.Config{Addr: ln.Addr().String(), Timeout: 5 * time.Second})
+	if err = rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err = rc.Auth("123456"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := fmt.Sprintf("echo %d", i)
+			data, err := rc.ExecCommand([]byte(cmd))
+			if err != nil || string(data) != cmd {
+				t.Errorf("unexpected response for %s: %q, %v", cmd, data, err)
+			}
+		}(i)
+	}
+	wg.Wait()
 }
@@ -0,0 +1,256 @@
+// Package rcontest 提供用于测试的进程内 Source RCON 服务端，
+// 支持预设命令响应、认证行为、拆分响应及故障注入(断开连接、写入乱码、延迟)
+package rcontest
+
+import (
+	"fmt"
+	"github.com/bang-go/steam/rcon"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultPassword = "123456"
+
+// sentinelTrailer srcds 在空 RESPONSE_VALUE 的回包之后追加的包的 body
+var sentinelTrailer = []byte{0x00, 0x01, 0x00, 0x00}
+
+// Fault 收到命令后注入的故障，按顺序执行 Delay、Garbage、Disconnect
+type Fault struct {
+	Delay      time.Duration //响应前等待
+	Garbage    []byte        //响应前写入的任意数据
+	Disconnect bool          //不返回响应，直接断开连接
+}
+
+type Config struct {
+	Password  string            //默认 DefaultPassword
+	Responses map[string]string //命令 -> 响应，优先完整匹配，其次匹配命令名(第一个单词)
+	// Handler 可选，处理未预设响应的命令，默认返回与 srcds 相同的未知命令提示
+	Handler func(command string) string
+	// EmptyResponseBeforeAuth 同 srcds，在 AUTH_RESPONSE 之前先返回一个空的 RESPONSE_VALUE
+	EmptyResponseBeforeAuth bool
+	RejectAuth              bool //总是认证失败
+	AuthFault               Fault
+	SplitSize               int //响应 body 按该长度拆分为多个包并分别写入，默认 rcon.MaxResponseBodySize
+	// SentinelTrailer 同 srcds，在空 RESPONSE_VALUE 的回包之后追加一个 body 为 0x00 0x01 0x00 0x00 的包
+	SentinelTrailer bool
+	Faults          map[string]Fault //命令 -> 故障，匹配规则同 Responses
+}
+
+// Server 在 127.0.0.1 的随机端口上运行，同一连接上的请求按顺序处理
+type Server struct {
+	Addr string
+	*Config
+	ln       net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	accepted int
+	commands []string
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer 启动服务端，监听失败时 panic，使用完毕后调用 Close
+func NewServer(cfg *Config) *Server {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if cfg.Password == "" {
+		cfg.Password = DefaultPassword
+	}
+	if cfg.SplitSize <= 0 {
+		cfg.SplitSize = int(rcon.MaxResponseBodySize)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("rcontest: 监听失败: %v", err))
+	}
+	s := &Server{Addr: ln.Addr().String(), Config: cfg, ln: ln, conns: map[net.Conn]struct{}{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Respond 设置命令的响应
+func (s *Server) Respond(command, response string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Responses == nil {
+		s.Responses = map[string]string{}
+	}
+	s.Responses[command] = response
+}
+
+// Inject 设置命令的故障，传入零值 Fault 时清除
+func (s *Server) Inject(command string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Faults == nil {
+		s.Faults = map[string]Fault{}
+	}
+	if fault.Delay == 0 && len(fault.Garbage) == 0 && !fault.Disconnect {
+		delete(s.Faults, command)
+		return
+	}
+	s.Faults[command] = fault
+}
+
+// Commands 返回已收到的命令
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Accepted 返回累计接受的连接数
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Disconnect 断开所有客户端连接，模拟服务端重启，之后仍可接受新连接
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Close 关闭监听及所有连接，等待处理中的请求返回
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.ln.Close()
+	s.Disconnect()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.accepted++
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	decoder, encoder := rcon.NewDecoder(conn), rcon.NewEncoder(conn)
+	authed := false
+	for {
+		p, err := decoder.Decode()
+		if err != nil {
+			return
+		}
+		switch {
+		case p.Type == rcon.DataTypeAuth:
+			if !s.inject(conn, s.AuthFault) {
+				return
+			}
+			var packets []*rcon.Packet
+			if s.EmptyResponseBeforeAuth {
+				packets = append(packets, rcon.NewPacket(rcon.DataTypeResponseValue, p.ID, nil))
+			}
+			authed = !s.RejectAuth && string(p.Body()) == s.Password
+			id := p.ID
+			if !authed {
+				id = rcon.RespAuthFailed
+			}
+			if encoder.Encode(append(packets, rcon.NewPacket(rcon.DataTypeAuthResponse, id, nil))...) != nil {
+				return
+			}
+		case !authed:
+			//未认证时只接受认证请求
+			return
+		case p.Type == rcon.DataTypeExecCommand:
+			command := string(p.Body())
+			response, fault := s.lookup(command)
+			if !s.inject(conn, fault) {
+				return
+			}
+			if !s.writeResponse(encoder, p.ID, response) {
+				return
+			}
+		case p.Type == rcon.DataTypeResponseValue:
+			packets := []*rcon.Packet{rcon.NewPacket(rcon.DataTypeResponseValue, p.ID, nil)}
+			if s.SentinelTrailer {
+				packets = append(packets, rcon.NewPacket(rcon.DataTypeResponseValue, p.ID, sentinelTrailer))
+			}
+			if encoder.Encode(packets...) != nil {
+				return
+			}
+		}
+	}
+}
+
+// 记录命令并返回响应及故障
+func (s *Server) lookup(command string) (response string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+	name, _, _ := strings.Cut(strings.TrimSpace(command), " ")
+	var ok bool
+	if fault, ok = s.Faults[command]; !ok {
+		fault = s.Faults[name]
+	}
+	if response, ok = s.Responses[command]; ok {
+		return
+	}
+	if response, ok = s.Responses[name]; ok {
+		return
+	}
+	if s.Handler != nil {
+		return s.Handler(command), fault
+	}
+	return fmt.Sprintf("Unknown command \"%s\"\n", name), fault
+}
+
+// 执行故障，需要断开连接时返回 false
+func (s *Server) inject(conn net.Conn, fault Fault) bool {
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+	if len(fault.Garbage) > 0 {
+		if _, err := conn.Write(fault.Garbage); err != nil {
+			return false
+		}
+	}
+	return !fault.Disconnect
+}
+
+// 按 SplitSize 拆分响应，每个包单独写入
+func (s *Server) writeResponse(encoder *rcon.Encoder, id int32, response string) bool {
+	body := []byte(response)
+	for {
+		n := min(len(body), s.SplitSize)
+		if encoder.Encode(rcon.NewPacket(rcon.DataTypeResponseValue, id, body[:n])) != nil {
+			return false
+		}
+		if body = body[n:]; len(body) == 0 {
+			return true
+		}
+	}
+}
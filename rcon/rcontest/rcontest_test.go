@@ -0,0 +1,78 @@
+package rcontest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/bang-go/steam/rcon"
+	"github.com/bang-go/steam/rcon/rcontest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	large := strings.Repeat("x", 10000)
+	srv := rcontest.NewServer(&rcontest.Config{
+		Responses:               map[string]string{"status": "hostname: test\n", "cvarlist": large},
+		EmptyResponseBeforeAuth: true,
+		SentinelTrailer:         true,
+		SplitSize:               1000,
+	})
+	defer srv.Close()
+
+	rc := rcon.New(&rcon.Config{Addr: srv.Addr, Timeout: time.Second})
+	if err := rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err := rc.Auth(rcontest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	for command, want := range map[string]string{"status": "hostname: test\n", "cvarlist": large, "foo bar": "Unknown command \"foo\"\n"} {
+		if data, err := rc.ExecCommand([]byte(command)); err != nil || string(data) != want {
+			t.Fatalf("unexpected response for %s: %d bytes, %v", command, len(data), err)
+		}
+	}
+
+	//延迟超过超时时间，本次调用放弃，连接仍可使用
+	srv.Inject("status", rcontest.Fault{Delay: 100 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rc.ExecCommandContext(ctx, []byte("status")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	srv.Inject("status", rcontest.Fault{})
+	if data, err := rc.ExecCommand([]byte("status")); err != nil || string(data) != "hostname: test\n" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+
+	srv.Inject("users", rcontest.Fault{Garbage: []byte{0xff, 0xff, 0xff, 0xff}})
+	if _, err := rc.ExecCommand([]byte("users")); !errors.Is(err, rcon.ErrBroken) || !errors.Is(err, rcon.ErrPacketSize) {
+		t.Fatalf("expected ErrPacketSize, got %v", err)
+	}
+
+	if err := rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.Auth(rcontest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	srv.Inject("quit", rcontest.Fault{Disconnect: true})
+	if _, err := rc.ExecCommand([]byte("quit")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken, got %v", err)
+	}
+	if got := srv.Commands(); len(got) != 7 || got[len(got)-1] != "quit" || srv.Accepted() != 2 {
+		t.Fatalf("unexpected commands %q, accepted %d", got, srv.Accepted())
+	}
+
+	rejected := rcontest.NewServer(&rcontest.Config{RejectAuth: true})
+	defer rejected.Close()
+	rc = rcon.New(&rcon.Config{Addr: rejected.Addr, Timeout: time.Second})
+	if err := rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err := rc.Auth(rcontest.DefaultPassword); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+}
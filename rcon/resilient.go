@@ -0,0 +1,257 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// ErrClosed 客户端已关闭
+var ErrClosed = errors.New("客户端已关闭")
+
+// 默认视为幂等(只读)的命令，连接断开后可以安全重试
+var defaultIdempotentCommands = map[string]bool{
+	"status":   true,
+	"stats":    true,
+	"version":  true,
+	"users":    true,
+	"cvarlist": true,
+	"maps":     true,
+	"listid":   true,
+	"listip":   true,
+	"find":     true,
+	"help":     true,
+	"echo":     true,
+	"ping":     true,
+}
+
+// DefaultIdempotent 只读命令(如 status、cvarlist、maps)视为幂等
+func DefaultIdempotent(command []byte) bool {
+	return defaultIdempotentCommands[strings.ToLower(commandName(command))]
+}
+
+type ResilientConfig struct {
+	Config
+	Password     string
+	MaxRetries   int                          //连接断开后的最大重试次数，默认 DefaultMaxRetries
+	MinBackoff   time.Duration                //第一次重试前的等待时间，之后翻倍，默认 DefaultMinBackoff
+	MaxBackoff   time.Duration                //最大等待时间，默认 DefaultMaxBackoff
+	Idempotent   func(command []byte) bool    //判断命令是否可以重试，默认 DefaultIdempotent
+	OnConnect    func(addr string)            //可选，连接并认证成功后调用
+	OnDisconnect func(addr string, err error) //可选，连接断开后调用
+}
+
+type resilientEntity struct {
+	*ResilientConfig
+	mu      sync.Mutex
+	rc      Rcon          //当前连接，断开后为空
+	dialing chan struct{} //正在建立连接时不为空，连接结束后关闭
+	closed  bool
+}
+
+// NewResilient 创建自动重连的客户端，记住密码，连接断开(EOF、重置、超时)后按退避策略重新连接并认证，幂等命令会自动重试。
+// 非幂等命令在发送后连接断开时不会重试，因为无法确定服务端是否已执行。
+func NewResilient(cfg *ResilientConfig) Rcon {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.Idempotent == nil {
+		cfg.Idempotent = DefaultIdempotent
+	}
+	return &resilientEntity{ResilientConfig: cfg}
+}
+
+func (s *resilientEntity) Dail() error {
+	return s.DialContext(context.Background())
+}
+
+func (s *resilientEntity) Auth(password string) error {
+	return s.AuthContext(context.Background(), password)
+}
+
+func (s *resilientEntity) ExecCommand(command []byte) ([]byte, error) {
+	return s.ExecCommandContext(context.Background(), command)
+}
+
+// DialContext 建立连接，已设置密码时同时认证
+func (s *resilientEntity) DialContext(ctx context.Context) (err error) {
+	_, err = s.retry(ctx, func(rc Rcon) error { return nil }, true)
+	return
+}
+
+// AuthContext 记住密码并认证当前连接，之后重连时自动使用该密码认证
+func (s *resilientEntity) AuthContext(ctx context.Context, password string) (err error) {
+	s.mu.Lock()
+	s.Password = password
+	rc := s.rc
+	s.mu.Unlock()
+	if rc == nil {
+		return s.DialContext(ctx)
+	}
+	if err = rc.AuthContext(ctx, password); err != nil && isConnError(err) {
+		s.invalidate(rc, err)
+	}
+	return
+}
+
+func (s *resilientEntity) ExecCommandContext(ctx context.Context, command []byte) (body []byte, err error) {
+	_, err = s.retry(ctx, func(rc Rcon) (err error) {
+		body, err = rc.ExecCommandContext(ctx, command)
+		return
+	}, s.Idempotent(command))
+	return
+}
+
+// 获取可用连接并执行 fn，连接失败或请求未发出时总是重试，请求发出后连接断开时仅在 retryable 时重试
+func (s *resilientEntity) retry(ctx context.Context, fn func(rc Rcon) error, retryable bool) (rc Rcon, err error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err = s.sleep(ctx, attempt); err != nil {
+				return
+			}
+		}
+		if rc, err = s.current(ctx); err != nil {
+			if errors.Is(err, ErrClosed) || errors.Is(err, ErrAuthFailed) || ctx.Err() != nil || attempt >= s.MaxRetries {
+				return
+			}
+			continue
+		}
+		//调用方的 ctx 结束只放弃本次调用，不影响连接；Config.Timeout 超时说明服务端无响应，重新连接
+		if err = fn(rc); err == nil || !isConnError(err) || ctx.Err() != nil {
+			return
+		}
+		s.invalidate(rc, err)
+		if (!retryable && !isUnsent(err)) || attempt >= s.MaxRetries {
+			return
+		}
+	}
+}
+
+// 返回当前连接，没有时重新连接并认证，连接及认证期间不持有锁，其他调用等待同一次连接完成
+func (s *resilientEntity) current(ctx context.Context) (rc Rcon, err error) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if s.rc != nil {
+			rc = s.rc
+			s.mu.Unlock()
+			return
+		}
+		if dialing := s.dialing; dialing != nil {
+			s.mu.Unlock()
+			select {
+			case <-dialing:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		dialing := make(chan struct{})
+		s.dialing = dialing
+		password := s.Password
+		s.mu.Unlock()
+
+		rc, err = s.dial(ctx, password)
+		s.mu.Lock()
+		s.dialing = nil
+		close(dialing)
+		if err == nil && s.closed {
+			rc.Close()
+			rc, err = nil, ErrClosed
+		}
+		if err == nil {
+			s.rc = rc
+		}
+		s.mu.Unlock()
+		if err == nil && s.OnConnect != nil {
+			s.OnConnect(s.Addr)
+		}
+		return
+	}
+}
+
+func (s *resilientEntity) dial(ctx context.Context, password string) (Rcon, error) {
+	rc := New(&s.Config)
+	if err := rc.DialContext(ctx); err != nil {
+		return nil, err
+	}
+	if password != "" {
+		if err := rc.AuthContext(ctx, password); err != nil {
+			rc.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// 关闭已断开的连接，rc 已被替换时忽略
+func (s *resilientEntity) invalidate(rc Rcon, err error) {
+	s.mu.Lock()
+	if s.rc != rc {
+		s.mu.Unlock()
+		return
+	}
+	s.rc = nil
+	s.mu.Unlock()
+	rc.Close()
+	if s.OnDisconnect != nil {
+		s.OnDisconnect(s.Addr, err)
+	}
+}
+
+// 指数退避并加入随机抖动，避免大量客户端同时重连
+func (s *resilientEntity) sleep(ctx context.Context, attempt int) error {
+	backoff := s.MinBackoff << (attempt - 1)
+	if backoff > s.MaxBackoff || backoff <= 0 {
+		backoff = s.MaxBackoff
+	}
+	backoff = backoff/2 + rand.N(backoff/2+1)
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (s *resilientEntity) Close() {
+	s.mu.Lock()
+	rc := s.rc
+	s.closed = true
+	s.rc = nil
+	s.mu.Unlock()
+	if rc != nil {
+		rc.Close()
+	}
+}
+
+// 连接相关的错误(断开、重置、超时、拒绝连接)，context.DeadlineExceeded 也实现了 net.Error
+func isConnError(err error) bool {
+	if errors.Is(err, ErrBroken) || errors.Is(err, ErrNotConnected) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,175 @@
+package rcon_test
+
+import (
+	"context"
+	"errors"
+	"github.com/bang-go/steam/rcon"
+	"github.com/bang-go/steam/rcon/rcontest"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResilient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var conns atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			n := conns.Add(1)
+			go func() {
+				defer conn.Close()
+				for {
+					id, typ, body, err := readFrame(conn)
+					if err != nil {
+						return
+					}
+					switch typ {
+					case rcon.DataTypeAuth:
+						if body != "123456" {
+							id = rcon.RespAuthFailed
+						}
+						_, _ = conn.Write(frame(id, rcon.DataTypeAuthResponse, ""))
+					case rcon.DataTypeExecCommand:
+						//奇数次连接收到命令后断开，模拟服务器重启
+						if n%2 == 1 {
+							return
+						}
+						_, _ = conn.Write(frame(id, rcon.DataTypeResponseValue, body))
+					case rcon.DataTypeResponseValue:
+						_, _ = conn.Write(frame(id, rcon.DataTypeResponseValue, ""))
+					}
+				}
+			}()
+		}
+	}()
+
+	var connected, disconnected atomic.Int32
+	rc := rcon.NewResilient(&rcon.ResilientConfig{
+		Config:       rcon.Config{Addr: ln.Addr().String(), Timeout: time.Second},
+		Password:     "123456",
+		MinBackoff:   time.Millisecond,
+		OnConnect:    func(addr string) { connected.Add(1) },
+		OnDisconnect: func(addr string, err error) { disconnected.Add(1) },
+	})
+	defer rc.Close()
+
+	data, err := rc.ExecCommand([]byte("status"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "status" || conns.Load() != 2 || connected.Load() != 2 || disconnected.Load() != 1 {
+		t.Fatalf("unexpected result: %q conns:%d connected:%d disconnected:%d", data, conns.Load(), connected.Load(), disconnected.Load())
+	}
+
+	//已连接时复用当前连接
+	if err = rc.DialContext(context.Background()); err != nil || conns.Load() != 2 {
+		t.Fatalf("unexpected dial: %v, conns:%d", err, conns.Load())
+	}
+	rc.Close()
+	if _, err = rc.ExecCommand([]byte("say hi")); !errors.Is(err, rcon.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+
+	rc = rcon.NewResilient(&rcon.ResilientConfig{Config: rcon.Config{Addr: ln.Addr().String()}, Password: "wrong", MinBackoff: time.Millisecond})
+	before := conns.Load()
+	if _, err = rc.ExecCommand([]byte("status")); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+	if conns.Load() != before+1 {
+		t.Fatal("expected no retry after auth failure")
+	}
+}
+
+func TestResilientNonIdempotent(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var conns atomic.Int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns.Add(1)
+			go func() {
+				defer conn.Close()
+				for {
+					id, typ, _, err := readFrame(conn)
+					if err != nil || typ == rcon.DataTypeExecCommand {
+						return
+					}
+					if typ == rcon.DataTypeAuth {
+						_, _ = conn.Write(frame(id, rcon.DataTypeAuthResponse, ""))
+					}
+				}
+			}()
+		}
+	}()
+	rc := rcon.NewResilient(&rcon.ResilientConfig{Config: rcon.Config{Addr: ln.Addr().String()}, Password: "123456", MinBackoff: time.Millisecond})
+	defer rc.Close()
+	if _, err = rc.ExecCommand([]byte("mp_restartgame 1")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken, got %v", err)
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("expected no retry, got %d connections", conns.Load())
+	}
+}
+
+func TestResilientServerRestart(t *testing.T) {
+	srv := rcontest.NewServer(&rcontest.Config{Responses: map[string]string{"status": "ok"}})
+	defer srv.Close()
+	rc := rcon.NewResilient(&rcon.ResilientConfig{Config: rcon.Config{Addr: srv.Addr, Timeout: time.Second}, Password: rcontest.DefaultPassword, MinBackoff: time.Millisecond})
+	defer rc.Close()
+	if data, err := rc.ExecCommand([]byte("status")); err != nil || string(data) != "ok" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+	//服务端断开空闲连接后，下一条命令重新连接
+	srv.Disconnect()
+	time.Sleep(50 * time.Millisecond)
+	if data, err := rc.ExecCommand([]byte("status")); err != nil || string(data) != "ok" || srv.Accepted() != 2 {
+		t.Fatalf("unexpected response: %q, %v, accepted %d", data, err, srv.Accepted())
+	}
+}
+
+func TestResilientHooksAndUnsentRetry(t *testing.T) {
+	srv := rcontest.NewServer(&rcontest.Config{Responses: map[string]string{"status": "ok", "mp_restartgame": ""}})
+	defer srv.Close()
+	var rc rcon.Rcon
+	var connected atomic.Int32
+	rc = rcon.NewResilient(&rcon.ResilientConfig{
+		Config:     rcon.Config{Addr: srv.Addr, Timeout: time.Second},
+		Password:   rcontest.DefaultPassword,
+		MinBackoff: time.Millisecond,
+		//回调中调用客户端不会死锁
+		OnConnect: func(addr string) {
+			if _, err := rc.ExecCommand([]byte("status")); err == nil {
+				connected.Add(1)
+			}
+		},
+	})
+	defer rc.Close()
+	if _, err := rc.ExecCommand([]byte("mp_restartgame 1")); err != nil || connected.Load() != 1 {
+		t.Fatalf("unexpected result: %v, connected %d", err, connected.Load())
+	}
+	//连接已断开时命令未发出，非幂等命令也会重新连接后执行
+	srv.Disconnect()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := rc.ExecCommand([]byte("mp_restartgame 1")); err != nil || connected.Load() != 2 {
+		t.Fatalf("unexpected result: %v, connected %d", err, connected.Load())
+	}
+	if got := srv.Commands(); len(got) != 4 || got[3] != "mp_restartgame 1" {
+		t.Fatalf("unexpected commands: %q", got)
+	}
+}
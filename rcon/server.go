@@ -0,0 +1,299 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultServerMaxConns   = 16
+	DefaultMaxAuthFailures  = 5
+	DefaultAuthFailureTime  = 30 * time.Second
+	MaxResponseBodySize     = MaxPacketSize - MinPacketSize //单个响应包 body 的最大长度，超过时拆分为多个包
+	serverAuthPacketTimeout = 10 * time.Second              //连接后等待认证的时间
+)
+
+// ErrServerClosed Serve 在 Close 之后返回
+var ErrServerClosed = errors.New("服务已关闭")
+
+// CommandHandler 处理已认证连接发来的命令，返回的输出超过单个包大小时会被拆分为多个 RESPONSE_VALUE 包。
+// ctx 在连接断开或服务关闭时取消，同一连接上的命令按顺序处理
+type CommandHandler func(ctx context.Context, remoteAddr net.Addr, command []byte) []byte
+
+// Server Source RCON 协议服务端
+type Server interface {
+	ListenAndServe() error
+	// Serve 在 ln 上接受连接，直到 Close 或 ln 出错
+	Serve(ln net.Listener) error
+	// Close 关闭监听及所有连接，等待处理中的命令返回
+	Close() error
+}
+
+// ServerConfig 认证失败的处理与 srcds 一致：在 AuthFailureTime 内失败 MaxAuthFailures 次后封禁该 IP BanDuration
+type ServerConfig struct {
+	Addr            string
+	Password        string //为空时拒绝所有认证
+	Handler         CommandHandler
+	MaxConns        int           //最大连接数，超过时直接关闭新连接，默认 DefaultServerMaxConns
+	MaxAuthFailures int           //默认 DefaultMaxAuthFailures，小于0时不封禁
+	AuthFailureTime time.Duration //统计认证失败次数的时间窗口，默认 DefaultAuthFailureTime
+	BanDuration     time.Duration //封禁时间，0 表示永久封禁(同 sv_rcon_banpenalty)
+	IdleTimeout     time.Duration //连接空闲超过该时间后关闭，0 表示不限制
+}
+
+// 单个 IP 的认证失败记录
+type authFailure struct {
+	count       int
+	first       time.Time
+	banned      bool
+	bannedUntil time.Time //零值表示永久
+}
+
+type serverEntity struct {
+	*ServerConfig
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	failures  map[string]*authFailure
+	closed    bool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+func NewServer(cfg *ServerConfig) Server {
+	if cfg.MaxConns <= 0 {
+		cfg.MaxConns = DefaultServerMaxConns
+	}
+	if cfg.MaxAuthFailures == 0 {
+		cfg.MaxAuthFailures = DefaultMaxAuthFailures
+	}
+	if cfg.AuthFailureTime <= 0 {
+		cfg.AuthFailureTime = DefaultAuthFailureTime
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &serverEntity{
+		ServerConfig: cfg,
+		listeners:    map[net.Listener]struct{}{},
+		conns:        map[net.Conn]struct{}{},
+		failures:     map[string]*authFailure{},
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+func (s *serverEntity) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+func (s *serverEntity) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return ErrServerClosed
+	}
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			continue
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// 记录连接，已关闭、连接数已满或 IP 被封禁时返回 false。持有锁时调用 wg.Add，保证 Close 等待所有连接
+func (s *serverEntity) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.conns) >= s.MaxConns || s.isBanned(remoteIP(conn)) {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *serverEntity) untrack(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+}
+
+func (s *serverEntity) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer s.untrack(conn)
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	decoder, encoder := NewDecoder(conn), NewEncoder(conn)
+	authed := false
+	for {
+		switch {
+		case !authed:
+			_ = conn.SetReadDeadline(time.Now().Add(serverAuthPacketTimeout))
+		case s.IdleTimeout > 0:
+			_ = conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		default:
+			_ = conn.SetReadDeadline(time.Time{})
+		}
+		p, err := decoder.Decode()
+		if err != nil {
+			return
+		}
+		switch {
+		case p.Type == DataTypeAuth:
+			authed = s.auth(conn, p)
+			//同 srcds，先返回一个空的 RESPONSE_VALUE，再返回 AUTH_RESPONSE，失败时 ID 为 RespAuthFailed
+			id := p.ID
+			if !authed {
+				id = RespAuthFailed
+			}
+			if encoder.Encode(NewPacket(DataTypeResponseValue, p.ID, nil), NewPacket(DataTypeAuthResponse, id, nil)) != nil {
+				return
+			}
+			if !authed && s.isBannedAddr(conn) {
+				return
+			}
+		case !authed:
+			//未认证时只接受认证请求
+			return
+		case p.Type == DataTypeExecCommand:
+			var output []byte
+			if s.Handler != nil {
+				output = s.Handler(ctx, conn.RemoteAddr(), p.Body())
+			}
+			if encoder.Encode(splitResponse(p.ID, output)...) != nil {
+				return
+			}
+		case p.Type == DataTypeResponseValue:
+			//客户端用于判断多包响应结束的空包，原样返回
+			if encoder.Encode(NewPacket(DataTypeResponseValue, p.ID, nil)) != nil {
+				return
+			}
+		}
+	}
+}
+
+// 校验密码，失败时记录次数并按需封禁
+func (s *serverEntity) auth(conn net.Conn, p *Packet) bool {
+	if s.Password != "" && string(p.Body()) == s.Password {
+		return true
+	}
+	if s.MaxAuthFailures < 0 {
+		return false
+	}
+	ip := remoteIP(conn)
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneFailures(now)
+	if s.isBanned(ip) {
+		return false
+	}
+	failure, ok := s.failures[ip]
+	if !ok || now.Sub(failure.first) > s.AuthFailureTime {
+		failure = &authFailure{first: now}
+		s.failures[ip] = failure
+	}
+	failure.count++
+	if failure.count >= s.MaxAuthFailures {
+		failure.banned = true
+		if s.BanDuration > 0 {
+			failure.bannedUntil = now.Add(s.BanDuration)
+		}
+	}
+	return false
+}
+
+// 清理已过统计窗口且未封禁的记录及已到期的封禁，调用方需持有 s.mu
+func (s *serverEntity) pruneFailures(now time.Time) {
+	for ip, failure := range s.failures {
+		expired := !failure.banned && now.Sub(failure.first) > s.AuthFailureTime
+		unbanned := failure.banned && !failure.bannedUntil.IsZero() && now.After(failure.bannedUntil)
+		if expired || unbanned {
+			delete(s.failures, ip)
+		}
+	}
+}
+
+func (s *serverEntity) isBannedAddr(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isBanned(remoteIP(conn))
+}
+
+// 调用方需持有 s.mu
+func (s *serverEntity) isBanned(ip string) bool {
+	failure, ok := s.failures[ip]
+	if !ok || !failure.banned {
+		return false
+	}
+	if !failure.bannedUntil.IsZero() && time.Now().After(failure.bannedUntil) {
+		delete(s.failures, ip)
+		return false
+	}
+	return true
+}
+
+func (s *serverEntity) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// 将输出按 MaxResponseBodySize 拆分为多个 RESPONSE_VALUE 包，输出为空时返回一个空包
+func splitResponse(id int32, output []byte) []*Packet {
+	var packets []*Packet
+	for len(output) > int(MaxResponseBodySize) {
+		packets = append(packets, NewPacket(DataTypeResponseValue, id, output[:MaxResponseBodySize]))
+		output = output[MaxResponseBodySize:]
+	}
+	return append(packets, NewPacket(DataTypeResponseValue, id, output))
+}
+
+func remoteIP(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
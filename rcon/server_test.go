@@ -0,0 +1,106 @@
+package rcon_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/bang-go/steam/rcon"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	large := bytes.Repeat([]byte("0123456789"), 1000)
+	srv := rcon.NewServer(&rcon.ServerConfig{
+		Password:        "123456",
+		MaxConns:        2,
+		MaxAuthFailures: 2,
+		Handler: func(ctx context.Context, remoteAddr net.Addr, command []byte) []byte {
+			if string(command) == "cvarlist" {
+				return large
+			}
+			return command
+		},
+	})
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	addr := ln.Addr().String()
+
+	rc := rcon.New(&rcon.Config{Addr: addr, Timeout: 5 * time.Second})
+	if err = rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err = rc.Auth("123456"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := rc.ExecCommand([]byte("status")); err != nil || string(data) != "status" {
+		t.Fatalf("unexpected response: %q, %v", data, err)
+	}
+	//超过单个包大小的输出拆分为多个包，客户端合并后与原始输出一致
+	if data, err := rc.ExecCommand([]byte("cvarlist")); err != nil || !bytes.Equal(data, large) {
+		t.Fatalf("unexpected response: %d bytes, %v", len(data), err)
+	}
+
+	//未认证时不接受命令
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = conn.Write(frame(1, rcon.DataTypeExecCommand, "status"))
+	if _, _, _, err = readFrame(conn); err == nil {
+		t.Fatal("expected connection closed")
+	}
+	conn.Close()
+
+	//连接数已满时关闭新连接
+	other := rcon.New(&rcon.Config{Addr: addr, Timeout: time.Second})
+	if err = other.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	if err = other.Auth("123456"); err != nil {
+		t.Fatal(err)
+	}
+	full := rcon.New(&rcon.Config{Addr: addr, Timeout: time.Second})
+	if err = full.Dail(); err == nil {
+		err = full.Auth("123456")
+	}
+	if !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken, got %v", err)
+	}
+	other.Close()
+
+	//连续认证失败后封禁 IP，已认证的连接不受影响
+	time.Sleep(50 * time.Millisecond)
+	bad := rcon.New(&rcon.Config{Addr: addr, Timeout: time.Second})
+	if err = bad.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	if err = bad.Auth("wrong"); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+	if err = bad.Auth("wrong"); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Fatalf("expected ErrAuthFailed, got %v", err)
+	}
+	if err = bad.Auth("123456"); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken after ban, got %v", err)
+	}
+	if _, err = rc.ExecCommand([]byte("status")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-served; !errors.Is(err, rcon.ErrServerClosed) {
+		t.Fatalf("expected ErrServerClosed, got %v", err)
+	}
+	if _, err = rc.ExecCommand([]byte("status")); !errors.Is(err, rcon.ErrBroken) {
+		t.Fatalf("expected ErrBroken, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package rcon
+
+import (
+	"bytes"
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+	"time"
+)
+
+const (
+	instrumentationName   = "github.com/bang-go/steam/rcon"
+	MetricCommandDuration = "rcon.command.duration" //命令耗时(秒)
+	MetricBytesSent       = "rcon.bytes.sent"       //发送的命令字节数
+	MetricBytesReceived   = "rcon.bytes.received"   //接收的响应字节数
+)
+
+// 链路及指标的属性名
+const (
+	AttrServerAddress = attribute.Key("server.address")
+	AttrCommand       = attribute.Key("rcon.command") //命令名，不包含参数
+	AttrOperation     = attribute.Key("rcon.operation")
+)
+
+type telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	sent     metric.Int64Counter
+	received metric.Int64Counter
+}
+
+// 未设置 provider 时使用 noop 实现
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+	var err error
+	if t.duration, err = meter.Float64Histogram(MetricCommandDuration, metric.WithUnit("s"), metric.WithDescription("RCON 操作耗时")); err != nil {
+		otel.Handle(err)
+	}
+	if t.sent, err = meter.Int64Counter(MetricBytesSent, metric.WithUnit("By")); err != nil {
+		otel.Handle(err)
+	}
+	if t.received, err = meter.Int64Counter(MetricBytesReceived, metric.WithUnit("By")); err != nil {
+		otel.Handle(err)
+	}
+	return t
+}
+
+// commandName 取命令的第一个词作为命令名，参数(可能包含密码)不记录
+func commandName(command []byte) string {
+	if fields := bytes.Fields(command); len(fields) > 0 {
+		return string(fields[0])
+	}
+	return ""
+}
+
+// 一次操作(dial、auth、exec)的 span 及指标记录
+type operation struct {
+	t     *telemetry
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+	attrs []attribute.KeyValue
+}
+
+func (t *telemetry) start(ctx context.Context, addr string, op string, attrs ...attribute.KeyValue) *operation {
+	attrs = append([]attribute.KeyValue{AttrServerAddress.String(addr), AttrOperation.String(op)}, attrs...)
+	ctx, span := t.tracer.Start(ctx, "rcon "+op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return &operation{t: t, ctx: ctx, span: span, start: time.Now(), attrs: attrs}
+}
+
+func (o *operation) end(sent int, received int, err error) {
+	defer o.span.End()
+	set := metric.WithAttributes(o.attrs...)
+	if o.t.duration != nil {
+		o.t.duration.Record(o.ctx, time.Since(o.start).Seconds(), set)
+	}
+	if o.t.sent != nil && sent > 0 {
+		o.t.sent.Add(o.ctx, int64(sent), set)
+	}
+	if o.t.received != nil && received > 0 {
+		o.t.received.Add(o.ctx, int64(received), set)
+	}
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+}
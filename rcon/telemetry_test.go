@@ -0,0 +1,99 @@
+package rcon_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/bang-go/steam/rcon"
+	"github.com/bang-go/steam/rcon/rcontest"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelemetry(t *testing.T) {
+	srv := rcontest.NewServer(&rcontest.Config{
+		Responses: map[string]string{"say": "ok"},
+		Faults:    map[string]rcontest.Fault{"quit": {Disconnect: true}},
+	})
+	defer srv.Close()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	rc := rcon.New(&rcon.Config{
+		Addr:           srv.Addr,
+		Timeout:        time.Second,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err := rc.Dail(); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if err := rc.Auth(rcontest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	command := []byte("say secret-arg")
+	if _, err := rc.ExecCommand(command); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.ExecCommand([]byte("quit")); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ended := spans.Ended()
+	var names []string
+	for _, span := range ended {
+		names = append(names, span.Name())
+	}
+	if strings.Join(names, ",") != "rcon dial,rcon auth,rcon exec,rcon exec" {
+		t.Fatalf("unexpected spans: %v", names)
+	}
+	attrs := map[string]string{}
+	for _, kv := range ended[2].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["rcon.command"] != "say" || attrs["server.address"] != srv.Addr || attrs["rcon.operation"] != "exec" {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+	if ended[3].Status().Code != codes.Error {
+		t.Fatalf("expected error status, got %v", ended[3].Status())
+	}
+	//密码及命令参数不记录
+	for _, span := range ended {
+		recorded := fmt.Sprint(span.Attributes(), span.Events())
+		if strings.Contains(recorded, rcontest.DefaultPassword) || strings.Contains(recorded, "secret-arg") {
+			t.Fatalf("sensitive value recorded in span %s: %s", span.Name(), recorded)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var count uint64
+	sums := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					count += dp.Count
+				}
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					sums[m.Name] += dp.Value
+				}
+			}
+		}
+	}
+	if count != 4 {
+		t.Fatalf("expected 4 recorded operations, got %d", count)
+	}
+	if sums[rcon.MetricBytesSent] != int64(len(command)+len("quit")) || sums[rcon.MetricBytesReceived] != int64(len("ok")) {
+		t.Fatalf("unexpected byte counters: %v", sums)
+	}
+}
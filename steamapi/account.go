@@ -2,14 +2,11 @@ package steamapi
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"github.com/bang-go/network/httpx"
-	"github.com/bang-go/util"
 	"net/http"
 	"time"
 )
 
+// Deprecated: 请求已统一由 Client.Call 按 Interface 及方法名拼接地址，这些常量不再使用，仅为兼容保留
 const (
 	// UrlGetAccountList 使用登录令牌获取游戏服务器帐户列表。
 	UrlGetAccountList = "https://api.steampowered.com/IGameServersService/GetAccountList/v1/"
@@ -35,6 +32,10 @@ const (
 	DefaultReqTimeout = 10 * time.Second
 )
 
+const (
+	InterfaceGameServersService = "IGameServersService"
+)
+
 type GameSteamServer struct {
 	SteamId     string `json:"steamid"`
 	AppId       int    `json:"appid"`
@@ -113,6 +114,7 @@ type GameServersService interface {
 type GameServersServiceConfig struct {
 	ApiKey  string
 	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
 }
 type gameServersServiceEntity struct {
 	*GameServersServiceConfig
@@ -122,200 +124,73 @@ func NewGameServersService(cfg *GameServersServiceConfig) GameServersService {
 	if cfg.Timeout == 0 {
 		cfg.Timeout = DefaultReqTimeout
 	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
 	return &gameServersServiceEntity{GameServersServiceConfig: cfg}
 }
 
 func (s *gameServersServiceEntity) GetAccountList() (resp *AccountListResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodGet,
-		Url:         UrlGetAccountList,
-		Params:      params,
-		ContentType: httpx.ContentJson,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
+	resp = &AccountListResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "GetAccountList", 1, http.MethodGet, nil, &resp.Response); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 func (s *gameServersServiceEntity) CreateAccount(appId int, memo string) (resp *CreatAccountResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	data := httpx.FormatFormData(map[string]string{"appid": util.IntToString(appId), "memo": memo})
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodPost,
-		Url:         UrlCreateAccount,
-		Params:      params,
-		Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
+	resp = &CreatAccountResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "CreateAccount", 1, http.MethodPost, Params{"appid": appId, "memo": memo}, &resp.Response); err != nil {
+		return nil, err
 	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
-	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 func (s *gameServersServiceEntity) SetMemo(steamId string, memo string) (err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	data := httpx.FormatFormData(map[string]string{"steamid": steamId, "memo": memo})
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodPost,
-		Url:         UrlSetMemo,
-		Params:      params,
-		Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
-	}
-	return
+	return s.Client.Call(context.Background(), InterfaceGameServersService, "SetMemo", 1, http.MethodPost, Params{"steamid": steamId, "memo": memo}, nil)
 }
 
 func (s *gameServersServiceEntity) ResetLoginToken(steamId string) (resp *ResetLoginTokenResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	data := httpx.FormatFormData(map[string]string{"steamid": steamId})
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodPost,
-		Url:         UrlResetLoginToken,
-		Params:      params,
-		Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
+	resp = &ResetLoginTokenResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "ResetLoginToken", 1, http.MethodPost, Params{"steamid": steamId}, &resp.Response); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 func (s *gameServersServiceEntity) DeleteAccount(steamId string) (err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	data := httpx.FormatFormData(map[string]string{"steamid": steamId})
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodPost,
-		Url:         UrlDeleteAccount,
-		Params:      params,
-		Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
-	}
-	return
+	return s.Client.Call(context.Background(), InterfaceGameServersService, "DeleteAccount", 1, http.MethodPost, Params{"steamid": steamId}, nil)
 }
 
 func (s *gameServersServiceEntity) GetAccountPublicInfo(steamId string) (resp *GetAccountPublicInfoResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey, "steamid": steamId}
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method: http.MethodGet,
-		Url:    UrlGetAccountPublicInfo,
-		Params: params,
-		//Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
+	resp = &GetAccountPublicInfoResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "GetAccountPublicInfo", 1, http.MethodGet, Params{"steamid": steamId}, &resp.Response); err != nil {
+		return nil, err
 	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
-	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 func (s *gameServersServiceEntity) QueryLoginToken(loginToken string) (resp *QueryLoginTokenResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey, "login_token": loginToken}
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method: http.MethodGet,
-		Url:    UrlQueryLoginToken,
-		Params: params,
-		//Body:        data,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
+	resp = &QueryLoginTokenResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "QueryLoginToken", 1, http.MethodGet, Params{"login_token": loginToken}, &resp.Response); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 // GetServerSteamIDsByIP ip:port
 func (s *gameServersServiceEntity) GetServerSteamIDsByIP(serverIps []string) (resp *GetServerSteamIDsByIPResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	for index, sid := range serverIps {
-		params[fmt.Sprintf("server_ips[%d]", index)] = sid
+	resp = &GetServerSteamIDsByIPResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "GetServerSteamIDsByIP", 1, http.MethodGet, Params{"server_ips": serverIps}, &resp.Response); err != nil {
+		return nil, err
 	}
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodGet,
-		Url:         UrlGetServerSteamIDsByIP,
-		Params:      params,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
-	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
 
 func (s *gameServersServiceEntity) GetServerIPsBySteamID(serverSteamIds []string) (resp *GetServerIPsBySteamIDResp, err error) {
-	client := httpx.New(&httpx.Config{Timeout: s.Timeout})
-	params := map[string]string{"key": s.ApiKey}
-	for index, sid := range serverSteamIds {
-		params[fmt.Sprintf("server_steamids[%d]", index)] = sid
-	}
-	httpResp, err := client.Send(context.Background(), &httpx.Request{
-		Method:      http.MethodGet,
-		Url:         UrlGetServerIPsBySteamID,
-		Params:      params,
-		ContentType: httpx.ContentForm,
-	})
-	if err != nil {
-		return
-	}
-	if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
-		return
+	resp = &GetServerIPsBySteamIDResp{}
+	if err = s.Client.Call(context.Background(), InterfaceGameServersService, "GetServerIPsBySteamID", 1, http.MethodGet, Params{"server_steamids": serverSteamIds}, &resp.Response); err != nil {
+		return nil, err
 	}
-	err = json.Unmarshal(httpResp.Content, &resp)
 	return
 }
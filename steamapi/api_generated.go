@@ -0,0 +1,362 @@
+// Code generated by steamapi-gen. DO NOT EDIT.
+
+package steamapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	InterfaceSteamNews = "ISteamNews"
+)
+
+// SteamNewsGetNewsForAppParams ISteamNews/GetNewsForApp 的请求参数
+type SteamNewsGetNewsForAppParams struct {
+	AppId     uint32 //AppID to retrieve news for
+	Maxlength uint32 //可选 Maximum length for the content to return, if this is 0 the full content is returned, if it's less then a blurb is generated to fit.
+	Enddate   uint32 //可选 Retrieve posts earlier than this date (unix epoch timestamp)
+	Count     uint32 //可选 # of posts to retrieve (default 20)
+	Feeds     string //可选 Comma-seperated list of feed names to return news for
+}
+
+func (p *SteamNewsGetNewsForAppParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["appid"] = p.AppId
+	if p.Maxlength != 0 {
+		params["maxlength"] = p.Maxlength
+	}
+	if p.Enddate != 0 {
+		params["enddate"] = p.Enddate
+	}
+	if p.Count != 0 {
+		params["count"] = p.Count
+	}
+	if p.Feeds != "" {
+		params["feeds"] = p.Feeds
+	}
+	return params
+}
+
+// SteamNewsService ISteamNews 接口，响应内容去除 response 外层后解析到 out
+type SteamNewsService interface {
+	GetNewsForApp(ctx context.Context, params *SteamNewsGetNewsForAppParams, out any) error
+}
+
+type SteamNewsServiceConfig struct {
+	ApiKey  string
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
+}
+type steamNewsServiceEntity struct {
+	*SteamNewsServiceConfig
+}
+
+func NewSteamNewsService(cfg *SteamNewsServiceConfig) SteamNewsService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
+	return &steamNewsServiceEntity{SteamNewsServiceConfig: cfg}
+}
+
+func (s *steamNewsServiceEntity) GetNewsForApp(ctx context.Context, params *SteamNewsGetNewsForAppParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamNews, "GetNewsForApp", 2, http.MethodGet, params.params(), out)
+}
+
+const (
+	InterfaceSteamUser = "ISteamUser"
+)
+
+// SteamUserGetFriendListParams ISteamUser/GetFriendList 的请求参数
+type SteamUserGetFriendListParams struct {
+	SteamId      uint64 //SteamID of user
+	Relationship string //可选 relationship type (ex: friend)
+}
+
+func (p *SteamUserGetFriendListParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["steamid"] = p.SteamId
+	if p.Relationship != "" {
+		params["relationship"] = p.Relationship
+	}
+	return params
+}
+
+// SteamUserGetPlayerBansParams ISteamUser/GetPlayerBans 的请求参数
+type SteamUserGetPlayerBansParams struct {
+	SteamIds string //Comma-delimited list of SteamIDs
+}
+
+func (p *SteamUserGetPlayerBansParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["steamids"] = p.SteamIds
+	return params
+}
+
+// SteamUserGetPlayerSummariesParams ISteamUser/GetPlayerSummaries 的请求参数
+type SteamUserGetPlayerSummariesParams struct {
+	SteamIds string //Comma-delimited list of SteamIDs (max: 100)
+}
+
+func (p *SteamUserGetPlayerSummariesParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["steamids"] = p.SteamIds
+	return params
+}
+
+// SteamUserGetUserGroupListParams ISteamUser/GetUserGroupList 的请求参数
+type SteamUserGetUserGroupListParams struct {
+	SteamId uint64 //SteamID of user
+}
+
+func (p *SteamUserGetUserGroupListParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["steamid"] = p.SteamId
+	return params
+}
+
+// SteamUserResolveVanityURLParams ISteamUser/ResolveVanityURL 的请求参数
+type SteamUserResolveVanityURLParams struct {
+	Vanityurl string //The vanity URL to get a SteamID for
+	UrlType   int32  //可选 The type of vanity URL. 1 (default): Individual profile, 2: Group, 3: Official game group
+}
+
+func (p *SteamUserResolveVanityURLParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["vanityurl"] = p.Vanityurl
+	if p.UrlType != 0 {
+		params["url_type"] = p.UrlType
+	}
+	return params
+}
+
+// SteamUserService ISteamUser 接口，响应内容去除 response 外层后解析到 out
+type SteamUserService interface {
+	GetFriendList(ctx context.Context, params *SteamUserGetFriendListParams, out any) error
+	GetPlayerBans(ctx context.Context, params *SteamUserGetPlayerBansParams, out any) error
+	GetPlayerSummaries(ctx context.Context, params *SteamUserGetPlayerSummariesParams, out any) error
+	GetUserGroupList(ctx context.Context, params *SteamUserGetUserGroupListParams, out any) error
+	ResolveVanityURL(ctx context.Context, params *SteamUserResolveVanityURLParams, out any) error
+}
+
+type SteamUserServiceConfig struct {
+	ApiKey  string
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
+}
+type steamUserServiceEntity struct {
+	*SteamUserServiceConfig
+}
+
+func NewSteamUserService(cfg *SteamUserServiceConfig) SteamUserService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
+	return &steamUserServiceEntity{SteamUserServiceConfig: cfg}
+}
+
+func (s *steamUserServiceEntity) GetFriendList(ctx context.Context, params *SteamUserGetFriendListParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUser, "GetFriendList", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserServiceEntity) GetPlayerBans(ctx context.Context, params *SteamUserGetPlayerBansParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUser, "GetPlayerBans", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserServiceEntity) GetPlayerSummaries(ctx context.Context, params *SteamUserGetPlayerSummariesParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUser, "GetPlayerSummaries", 2, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserServiceEntity) GetUserGroupList(ctx context.Context, params *SteamUserGetUserGroupListParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUser, "GetUserGroupList", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserServiceEntity) ResolveVanityURL(ctx context.Context, params *SteamUserResolveVanityURLParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUser, "ResolveVanityURL", 1, http.MethodGet, params.params(), out)
+}
+
+const (
+	InterfaceSteamUserStats = "ISteamUserStats"
+)
+
+// SteamUserStatsGetGlobalStatsForGameParams ISteamUserStats/GetGlobalStatsForGame 的请求参数
+type SteamUserStatsGetGlobalStatsForGameParams struct {
+	AppId     uint32   //AppID that we're getting global stats for
+	Count     uint32   //Number of stats get data for
+	Name      []string //Names of stat to get data for
+	Startdate uint32   //可选 Start date for daily totals (unix epoch timestamp)
+	Enddate   uint32   //可选 End date for daily totals (unix epoch timestamp)
+}
+
+func (p *SteamUserStatsGetGlobalStatsForGameParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["appid"] = p.AppId
+	params["count"] = p.Count
+	params["name"] = p.Name
+	if p.Startdate != 0 {
+		params["startdate"] = p.Startdate
+	}
+	if p.Enddate != 0 {
+		params["enddate"] = p.Enddate
+	}
+	return params
+}
+
+// SteamUserStatsGetNumberOfCurrentPlayersParams ISteamUserStats/GetNumberOfCurrentPlayers 的请求参数
+type SteamUserStatsGetNumberOfCurrentPlayersParams struct {
+	AppId uint32 //AppID that we're getting user count for
+}
+
+func (p *SteamUserStatsGetNumberOfCurrentPlayersParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["appid"] = p.AppId
+	return params
+}
+
+// SteamUserStatsGetSchemaForGameParams ISteamUserStats/GetSchemaForGame 的请求参数
+type SteamUserStatsGetSchemaForGameParams struct {
+	AppId uint32 //appid of game
+	L     string //可选 localized langauge to return (english, french, etc.)
+}
+
+func (p *SteamUserStatsGetSchemaForGameParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	params["appid"] = p.AppId
+	if p.L != "" {
+		params["l"] = p.L
+	}
+	return params
+}
+
+// SteamUserStatsService ISteamUserStats 接口，响应内容去除 response 外层后解析到 out
+type SteamUserStatsService interface {
+	GetGlobalStatsForGame(ctx context.Context, params *SteamUserStatsGetGlobalStatsForGameParams, out any) error
+	GetNumberOfCurrentPlayers(ctx context.Context, params *SteamUserStatsGetNumberOfCurrentPlayersParams, out any) error
+	GetSchemaForGame(ctx context.Context, params *SteamUserStatsGetSchemaForGameParams, out any) error
+}
+
+type SteamUserStatsServiceConfig struct {
+	ApiKey  string
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
+}
+type steamUserStatsServiceEntity struct {
+	*SteamUserStatsServiceConfig
+}
+
+func NewSteamUserStatsService(cfg *SteamUserStatsServiceConfig) SteamUserStatsService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
+	return &steamUserStatsServiceEntity{SteamUserStatsServiceConfig: cfg}
+}
+
+func (s *steamUserStatsServiceEntity) GetGlobalStatsForGame(ctx context.Context, params *SteamUserStatsGetGlobalStatsForGameParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUserStats, "GetGlobalStatsForGame", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserStatsServiceEntity) GetNumberOfCurrentPlayers(ctx context.Context, params *SteamUserStatsGetNumberOfCurrentPlayersParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUserStats, "GetNumberOfCurrentPlayers", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamUserStatsServiceEntity) GetSchemaForGame(ctx context.Context, params *SteamUserStatsGetSchemaForGameParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamUserStats, "GetSchemaForGame", 2, http.MethodGet, params.params(), out)
+}
+
+const (
+	InterfaceSteamWebAPIUtil = "ISteamWebAPIUtil"
+)
+
+// SteamWebAPIUtilGetServerInfoParams ISteamWebAPIUtil/GetServerInfo 的请求参数
+type SteamWebAPIUtilGetServerInfoParams struct {
+}
+
+func (p *SteamWebAPIUtilGetServerInfoParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	return params
+}
+
+// SteamWebAPIUtilGetSupportedAPIListParams ISteamWebAPIUtil/GetSupportedAPIList 的请求参数
+type SteamWebAPIUtilGetSupportedAPIListParams struct {
+}
+
+func (p *SteamWebAPIUtilGetSupportedAPIListParams) params() Params {
+	params := Params{}
+	if p == nil {
+		return params
+	}
+	return params
+}
+
+// SteamWebAPIUtilService ISteamWebAPIUtil 接口，响应内容去除 response 外层后解析到 out
+type SteamWebAPIUtilService interface {
+	GetServerInfo(ctx context.Context, params *SteamWebAPIUtilGetServerInfoParams, out any) error
+	GetSupportedAPIList(ctx context.Context, params *SteamWebAPIUtilGetSupportedAPIListParams, out any) error
+}
+
+type SteamWebAPIUtilServiceConfig struct {
+	ApiKey  string
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建
+}
+type steamWebAPIUtilServiceEntity struct {
+	*SteamWebAPIUtilServiceConfig
+}
+
+func NewSteamWebAPIUtilService(cfg *SteamWebAPIUtilServiceConfig) SteamWebAPIUtilService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Timeout: cfg.Timeout})
+	}
+	return &steamWebAPIUtilServiceEntity{SteamWebAPIUtilServiceConfig: cfg}
+}
+
+func (s *steamWebAPIUtilServiceEntity) GetServerInfo(ctx context.Context, params *SteamWebAPIUtilGetServerInfoParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamWebAPIUtil, "GetServerInfo", 1, http.MethodGet, params.params(), out)
+}
+
+func (s *steamWebAPIUtilServiceEntity) GetSupportedAPIList(ctx context.Context, params *SteamWebAPIUtilGetSupportedAPIListParams, out any) error {
+	return s.Client.Call(ctx, InterfaceSteamWebAPIUtil, "GetSupportedAPIList", 1, http.MethodGet, params.params(), out)
+}
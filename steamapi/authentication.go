@@ -0,0 +1,280 @@
+package steamapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"github.com/bang-go/steam/steamid"
+	"google.golang.org/protobuf/proto"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	InterfaceAuthentication = "IAuthenticationService"
+)
+
+const (
+	DefaultDeviceFriendlyName = "bang-go/steam"
+	DefaultPollInterval       = 5 * time.Second //Steam 未返回轮询间隔时使用
+)
+
+// AuthState 登录会话状态
+type AuthState int
+
+const (
+	AuthStateInit             AuthState = iota //未开始
+	AuthStateNeedGuardCode                     //需要提交邮箱或令牌验证码，同时允许确认时也可以直接轮询
+	AuthStateNeedConfirmation                  //需要在邮箱或移动端确认，确认后轮询即可获取令牌
+	AuthStatePolling                           //等待轮询获取令牌
+	AuthStateAuthenticated                     //已获取令牌
+)
+
+type AuthTokens struct {
+	AccountName  string
+	RefreshToken string
+	AccessToken  string
+	NewGuardData string //机器验证信息，下次登录时通过 GuardData 提交可跳过邮箱验证
+}
+
+// AuthSession 通过账号密码登录 Steam 的会话，状态变化: Init -> (NeedGuardCode|NeedConfirmation) -> Polling -> Authenticated
+type AuthSession interface {
+	// Begin 获取 RSA 公钥加密密码并开始登录
+	Begin(ctx context.Context, accountName string, password string) error
+	// SubmitGuardCode 提交邮箱(k_EAuthSessionGuardType_EmailCode)或令牌(k_EAuthSessionGuardType_DeviceCode)验证码
+	SubmitGuardCode(ctx context.Context, code string, codeType steampb.EAuthSessionGuardType) error
+	// Poll 轮询一次登录状态，获取到令牌时返回 true
+	Poll(ctx context.Context) (bool, error)
+	// Wait 按 Steam 返回的间隔持续轮询，直到获取令牌或 ctx 结束
+	Wait(ctx context.Context) error
+	State() AuthState
+	AllowedConfirmations() []steampb.EAuthSessionGuardType
+	SteamId() steamid.SteamID
+	Tokens() AuthTokens
+}
+
+type AuthSessionConfig struct {
+	Client             Client //可选，默认 New(&Config{})，认证接口不需要 Web API 密钥
+	DeviceFriendlyName string //默认 DefaultDeviceFriendlyName
+	PlatformType       steampb.EAuthTokenPlatformType
+	WebsiteId          string //可选，如 Community、Mobile
+	GuardData          string //上次登录获得的 NewGuardData
+	RememberLogin      bool
+}
+
+type authSessionEntity struct {
+	*AuthSessionConfig
+	state     AuthState
+	clientId  uint64
+	requestId []byte
+	interval  time.Duration
+	steamId   uint64
+	allowed   []steampb.EAuthSessionGuardType
+	tokens    AuthTokens
+}
+
+func NewAuthSession(cfg *AuthSessionConfig) AuthSession {
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{})
+	}
+	if cfg.DeviceFriendlyName == "" {
+		cfg.DeviceFriendlyName = DefaultDeviceFriendlyName
+	}
+	if cfg.PlatformType == steampb.EAuthTokenPlatformType_k_EAuthTokenPlatformType_Unknown {
+		cfg.PlatformType = steampb.EAuthTokenPlatformType_k_EAuthTokenPlatformType_WebBrowser
+	}
+	return &authSessionEntity{AuthSessionConfig: cfg}
+}
+
+func (s *authSessionEntity) Begin(ctx context.Context, accountName string, password string) (err error) {
+	if s.state != AuthStateInit {
+		return fmt.Errorf("当前状态不允许开始登录, state:%d", s.state)
+	}
+	encrypted, timestamp, err := s.encryptPassword(ctx, accountName, password)
+	if err != nil {
+		return
+	}
+	req := &steampb.CAuthentication_BeginAuthSessionViaCredentials_Request{
+		DeviceFriendlyName:  proto.String(s.DeviceFriendlyName),
+		AccountName:         proto.String(accountName),
+		EncryptedPassword:   proto.String(encrypted),
+		EncryptionTimestamp: proto.Uint64(timestamp),
+		RememberLogin:       proto.Bool(s.RememberLogin),
+		PlatformType:        s.PlatformType.Enum(),
+		Persistence:         steampb.ESessionPersistence_k_ESessionPersistence_Persistent.Enum(),
+		DeviceDetails: &steampb.CAuthentication_DeviceDetails{
+			DeviceFriendlyName: proto.String(s.DeviceFriendlyName),
+			PlatformType:       s.PlatformType.Enum(),
+		},
+	}
+	if s.WebsiteId != "" {
+		req.WebsiteId = proto.String(s.WebsiteId)
+	}
+	if s.GuardData != "" {
+		req.GuardData = proto.String(s.GuardData)
+	}
+	resp := &steampb.CAuthentication_BeginAuthSessionViaCredentials_Response{}
+	if err = s.Client.Call(ctx, InterfaceAuthentication, "BeginAuthSessionViaCredentials", 1, http.MethodPost, req, resp); err != nil {
+		return
+	}
+	s.clientId = resp.GetClientId()
+	s.requestId = resp.GetRequestId()
+	s.steamId = resp.GetSteamid()
+	s.interval = time.Duration(resp.GetInterval() * float32(time.Second))
+	if s.interval <= 0 {
+		s.interval = DefaultPollInterval
+	}
+	s.tokens.AccountName = accountName
+	s.allowed = s.allowed[:0]
+	for _, c := range resp.GetAllowedConfirmations() {
+		s.allowed = append(s.allowed, c.GetConfirmationType())
+	}
+	s.state = nextAuthState(s.allowed)
+	return
+}
+
+// 无需验证时直接轮询，可提交验证码时优先使用验证码，否则等待用户确认
+func nextAuthState(allowed []steampb.EAuthSessionGuardType) AuthState {
+	state := AuthStateNeedConfirmation
+	for _, t := range allowed {
+		switch t {
+		case steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_None:
+			return AuthStatePolling
+		case steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_EmailCode, steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceCode:
+			state = AuthStateNeedGuardCode
+		}
+	}
+	return state
+}
+
+func (s *authSessionEntity) confirmable() bool {
+	for _, t := range s.allowed {
+		switch t {
+		case steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceConfirmation, steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_EmailConfirmation:
+			return true
+		}
+	}
+	return false
+}
+
+// 使用 GetPasswordRSAPublicKey 返回的公钥以 PKCS#1 v1.5 加密密码
+func (s *authSessionEntity) encryptPassword(ctx context.Context, accountName string, password string) (encrypted string, timestamp uint64, err error) {
+	resp := &steampb.CAuthentication_GetPasswordRSAPublicKey_Response{}
+	req := &steampb.CAuthentication_GetPasswordRSAPublicKey_Request{AccountName: proto.String(accountName)}
+	if err = s.Client.Call(ctx, InterfaceAuthentication, "GetPasswordRSAPublicKey", 1, http.MethodGet, req, resp); err != nil {
+		return
+	}
+	mod, ok := new(big.Int).SetString(resp.GetPublickeyMod(), 16)
+	if !ok {
+		err = fmt.Errorf("异常的publickey_mod: %s", resp.GetPublickeyMod())
+		return
+	}
+	exp, err := strconv.ParseInt(resp.GetPublickeyExp(), 16, 32)
+	if err != nil {
+		err = fmt.Errorf("异常的publickey_exp: %s", resp.GetPublickeyExp())
+		return
+	}
+	data, err := rsa.EncryptPKCS1v15(rand.Reader, &rsa.PublicKey{N: mod, E: int(exp)}, []byte(password))
+	if err != nil {
+		return
+	}
+	encrypted = base64.StdEncoding.EncodeToString(data)
+	timestamp = resp.GetTimestamp()
+	return
+}
+
+func (s *authSessionEntity) SubmitGuardCode(ctx context.Context, code string, codeType steampb.EAuthSessionGuardType) (err error) {
+	if s.state != AuthStateNeedGuardCode {
+		return fmt.Errorf("当前状态不允许提交验证码, state:%d", s.state)
+	}
+	req := &steampb.CAuthentication_UpdateAuthSessionWithSteamGuardCode_Request{
+		ClientId: proto.Uint64(s.clientId),
+		Steamid:  proto.Uint64(s.steamId),
+		Code:     proto.String(code),
+		CodeType: codeType.Enum(),
+	}
+	resp := &steampb.CAuthentication_UpdateAuthSessionWithSteamGuardCode_Response{}
+	if err = s.Client.Call(ctx, InterfaceAuthentication, "UpdateAuthSessionWithSteamGuardCode", 1, http.MethodPost, req, resp); err != nil {
+		return
+	}
+	s.state = AuthStatePolling
+	return
+}
+
+func (s *authSessionEntity) Poll(ctx context.Context) (done bool, err error) {
+	switch s.state {
+	case AuthStateAuthenticated:
+		return true, nil
+	case AuthStatePolling, AuthStateNeedConfirmation:
+	case AuthStateNeedGuardCode:
+		//同时允许在邮箱或移动端确认时，用户确认后轮询即可获取令牌，无需提交验证码
+		if !s.confirmable() {
+			return false, fmt.Errorf("当前状态不允许轮询, state:%d", s.state)
+		}
+	default:
+		return false, fmt.Errorf("当前状态不允许轮询, state:%d", s.state)
+	}
+	req := &steampb.CAuthentication_PollAuthSessionStatus_Request{
+		ClientId:  proto.Uint64(s.clientId),
+		RequestId: s.requestId,
+	}
+	resp := &steampb.CAuthentication_PollAuthSessionStatus_Response{}
+	if err = s.Client.Call(ctx, InterfaceAuthentication, "PollAuthSessionStatus", 1, http.MethodPost, req, resp); err != nil {
+		return
+	}
+	if resp.NewClientId != nil {
+		s.clientId = resp.GetNewClientId()
+	}
+	if resp.GetRefreshToken() == "" {
+		return
+	}
+	s.tokens.RefreshToken = resp.GetRefreshToken()
+	s.tokens.AccessToken = resp.GetAccessToken()
+	s.tokens.NewGuardData = resp.GetNewGuardData()
+	if resp.GetAccountName() != "" {
+		s.tokens.AccountName = resp.GetAccountName()
+	}
+	s.state = AuthStateAuthenticated
+	return true, nil
+}
+
+func (s *authSessionEntity) Wait(ctx context.Context) (err error) {
+	for {
+		var done bool
+		if done, err = s.Poll(ctx); err != nil || done {
+			return
+		}
+		timer := time.NewTimer(s.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *authSessionEntity) State() AuthState {
+	return s.state
+}
+
+func (s *authSessionEntity) AllowedConfirmations() []steampb.EAuthSessionGuardType {
+	return s.allowed
+}
+
+func (s *authSessionEntity) SteamId() steamid.SteamID {
+	if s.steamId == 0 {
+		return nil
+	}
+	sid, _ := steamid.New(strconv.FormatUint(s.steamId, 10))
+	return sid
+}
+
+func (s *authSessionEntity) Tokens() AuthTokens {
+	return s.tokens
+}
@@ -0,0 +1,174 @@
+package steamapi_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// 模拟 IAuthenticationService: 密码为 hunter2，需要令牌验证码 ABCDE，提交后第二次轮询返回令牌
+func TestAuthSession(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polls := 0
+	codeAccepted := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		input, _ := base64.StdEncoding.DecodeString(r.Form.Get("input_protobuf_encoded"))
+		var out proto.Message
+		switch r.URL.Path {
+		case "/IAuthenticationService/GetPasswordRSAPublicKey/v1/":
+			out = &steampb.CAuthentication_GetPasswordRSAPublicKey_Response{
+				PublickeyMod: proto.String(key.N.Text(16)),
+				PublickeyExp: proto.String(fmt.Sprintf("%x", key.E)),
+				Timestamp:    proto.Uint64(42),
+			}
+		case "/IAuthenticationService/BeginAuthSessionViaCredentials/v1/":
+			req := &steampb.CAuthentication_BeginAuthSessionViaCredentials_Request{}
+			_ = proto.Unmarshal(input, req)
+			encrypted, _ := base64.StdEncoding.DecodeString(req.GetEncryptedPassword())
+			password, err := rsa.DecryptPKCS1v15(nil, key, encrypted)
+			if err != nil || string(password) != "hunter2" || req.GetEncryptionTimestamp() != 42 {
+				w.Header().Set("X-eresult", "5") //InvalidPassword
+				return
+			}
+			out = &steampb.CAuthentication_BeginAuthSessionViaCredentials_Response{
+				ClientId:  proto.Uint64(1),
+				RequestId: []byte("req"),
+				Interval:  proto.Float32(0.01),
+				Steamid:   proto.Uint64(76561199181487706),
+				AllowedConfirmations: []*steampb.CAuthentication_AllowedConfirmation{
+					{ConfirmationType: steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceCode.Enum()},
+				},
+			}
+		case "/IAuthenticationService/UpdateAuthSessionWithSteamGuardCode/v1/":
+			req := &steampb.CAuthentication_UpdateAuthSessionWithSteamGuardCode_Request{}
+			_ = proto.Unmarshal(input, req)
+			if req.GetCode() != "ABCDE" || req.GetSteamid() != 76561199181487706 {
+				w.Header().Set("X-eresult", "65") //InvalidLoginAuthCode
+				return
+			}
+			codeAccepted = true
+			out = &steampb.CAuthentication_UpdateAuthSessionWithSteamGuardCode_Response{}
+		case "/IAuthenticationService/PollAuthSessionStatus/v1/":
+			req := &steampb.CAuthentication_PollAuthSessionStatus_Request{}
+			_ = proto.Unmarshal(input, req)
+			polls++
+			resp := &steampb.CAuthentication_PollAuthSessionStatus_Response{}
+			if codeAccepted && polls > 1 && string(req.GetRequestId()) == "req" {
+				resp.RefreshToken = proto.String("refresh")
+				resp.AccessToken = proto.String("access")
+				resp.AccountName = proto.String("bot")
+			}
+			out = resp
+		}
+		data, _ := proto.Marshal(out)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	session := steamapi.NewAuthSession(&steamapi.AuthSessionConfig{Client: steamapi.New(&steamapi.Config{Host: srv.URL})})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = session.Begin(ctx, "bot", "wrong"); err == nil {
+		t.Fatal("expected wrong password to be rejected")
+	}
+	if err = session.Begin(ctx, "bot", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if session.State() != steamapi.AuthStateNeedGuardCode || session.SteamId().RenderSteamID64() != 76561199181487706 {
+		t.Fatalf("unexpected state: %d", session.State())
+	}
+	if _, err = session.Poll(ctx); err == nil {
+		t.Fatal("expected poll before guard code to fail")
+	}
+	if err = session.SubmitGuardCode(ctx, "ABCDE", steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceCode); err != nil {
+		t.Fatal(err)
+	}
+	if err = session.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := session.Tokens(); tokens.RefreshToken != "refresh" || tokens.AccessToken != "access" || session.State() != steamapi.AuthStateAuthenticated {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+}
+
+func TestAuthSessionDefaultClient(t *testing.T) {
+	cfg := &steamapi.AuthSessionConfig{}
+	session := steamapi.NewAuthSession(cfg)
+	if cfg.Client == nil || session.State() != steamapi.AuthStateInit {
+		t.Fatal("expected default client")
+	}
+}
+
+// 同时允许令牌验证码和移动端确认时，用户在移动端确认后直接轮询即可获取令牌
+func TestAuthSessionConfirmWithoutCode(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var out proto.Message
+		switch r.URL.Path {
+		case "/IAuthenticationService/GetPasswordRSAPublicKey/v1/":
+			out = &steampb.CAuthentication_GetPasswordRSAPublicKey_Response{
+				PublickeyMod: proto.String(key.N.Text(16)),
+				PublickeyExp: proto.String(fmt.Sprintf("%x", key.E)),
+				Timestamp:    proto.Uint64(42),
+			}
+		case "/IAuthenticationService/BeginAuthSessionViaCredentials/v1/":
+			out = &steampb.CAuthentication_BeginAuthSessionViaCredentials_Response{
+				ClientId:  proto.Uint64(1),
+				RequestId: []byte("req"),
+				Interval:  proto.Float32(0.01),
+				Steamid:   proto.Uint64(76561199181487706),
+				AllowedConfirmations: []*steampb.CAuthentication_AllowedConfirmation{
+					{ConfirmationType: steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceCode.Enum()},
+					{ConfirmationType: steampb.EAuthSessionGuardType_k_EAuthSessionGuardType_DeviceConfirmation.Enum()},
+				},
+			}
+		case "/IAuthenticationService/PollAuthSessionStatus/v1/":
+			polls++
+			resp := &steampb.CAuthentication_PollAuthSessionStatus_Response{}
+			if polls > 2 {
+				resp.RefreshToken = proto.String("refresh")
+				resp.AccessToken = proto.String("access")
+			}
+			out = resp
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := proto.Marshal(out)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	session := steamapi.NewAuthSession(&steamapi.AuthSessionConfig{Client: steamapi.New(&steamapi.Config{Host: srv.URL})})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = session.Begin(ctx, "bot", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if session.State() != steamapi.AuthStateNeedGuardCode {
+		t.Fatalf("unexpected state: %d", session.State())
+	}
+	if err = session.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tokens := session.Tokens(); tokens.RefreshToken != "refresh" || tokens.AccessToken != "access" || session.State() != steamapi.AuthStateAuthenticated || polls != 3 {
+		t.Fatalf("unexpected tokens: %+v, polls: %d", tokens, polls)
+	}
+}
@@ -0,0 +1,147 @@
+package steamapi
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheCapacity = 1024 //内存缓存默认最多保存的响应数
+)
+
+// CacheStore 响应缓存存储，可替换为 redis 等外部存储，实现需要并发安全
+type CacheStore interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// CacheConfig 只读(GET)调用的响应缓存，TTL 大于0的接口才会缓存，并发的相同请求只会发出一次
+type CacheConfig struct {
+	Store      CacheStore               //默认 NewMemoryCacheStore(DefaultCacheCapacity)
+	DefaultTTL time.Duration            //未在 TTLs 中配置的接口使用的 TTL，0 表示不缓存
+	TTLs       map[string]time.Duration //按接口配置 TTL，键为 "IGameServersService/GetAccountList"
+	//缓存键默认不包含 key 和 access_token，不同凭据的相同请求共享响应，access_token 刷新后仍能命中；
+	//返回内容与账号相关(如 GetAccountList)且多个账号共用 Store 时开启，按凭据分别缓存
+	PerCredential bool
+}
+
+func (c *CacheConfig) ttl(iface string, method string) time.Duration {
+	if ttl, ok := c.TTLs[iface+"/"+method]; ok {
+		return ttl
+	}
+	return c.DefaultTTL
+}
+
+// 缓存键由接口、版本及参数计算，perCredential 为 false 时不包含 key 和 access_token；
+// 使用哈希避免 key 等参数明文写入存储
+func cacheKey(iface string, method string, version int, params map[string]string, perCredential bool) string {
+	values := url.Values{}
+	for name, value := range params {
+		if !perCredential && (name == ParamKey || name == ParamAccessToken) {
+			continue
+		}
+		values.Set(name, value)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/v%d?%s", iface, method, version, values.Encode())))
+	return "steamapi:" + hex.EncodeToString(sum[:])
+}
+
+// 命中缓存时直接返回缓存的响应内容，未命中时合并并发的相同请求，成功的响应写入缓存。
+// 合并后的请求不受某个调用方 ctx 取消的影响，只受 Config.Timeout 限制，每个调用方按自己的 ctx 等待
+func (s *clientEntity) sendCached(ctx context.Context, req *Request) (resp *Response, err error) {
+	ttl := time.Duration(0)
+	if s.Cache != nil && req.HttpMethod == http.MethodGet {
+		ttl = s.Cache.ttl(req.Interface, req.Method)
+	}
+	if ttl <= 0 {
+		return s.send(ctx, req)
+	}
+	key := cacheKey(req.Interface, req.Method, req.Version, req.Params, s.Cache.PerCredential)
+	if content, ok := s.Cache.Store.Get(key); ok {
+		return &Response{StatusCode: http.StatusOK, Content: content, Cached: true}, nil
+	}
+	ch := s.group.DoChan(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.Timeout)
+		defer cancel()
+		resp, err := s.send(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if err = decodeResponse(req, resp, nil); err != nil {
+			return nil, err
+		}
+		s.Cache.Store.Set(key, resp.Content, ttl)
+		return resp, nil
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*Response), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type memoryCacheItem struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type memoryCacheStore struct {
+	capacity int
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	order    *list.List //最近使用的在前
+}
+
+// NewMemoryCacheStore 基于 LRU 的内存缓存，超过 capacity 时淘汰最久未使用的响应
+func NewMemoryCacheStore(capacity int) CacheStore {
+	if capacity <= 0 {
+		capacity = DefaultCacheCapacity
+	}
+	return &memoryCacheStore{capacity: capacity, items: map[string]*list.Element{}, order: list.New()}
+}
+
+func (m *memoryCacheStore) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if time.Now().After(item.expiresAt) {
+		m.order.Remove(elem)
+		delete(m.items, key)
+		return nil, false
+	}
+	m.order.MoveToFront(elem)
+	return item.value, true
+}
+
+func (m *memoryCacheStore) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := &memoryCacheItem{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := m.items[key]; ok {
+		elem.Value = item
+		m.order.MoveToFront(elem)
+		return
+	}
+	m.items[key] = m.order.PushFront(item)
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
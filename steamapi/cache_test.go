@@ -0,0 +1,144 @@
+package steamapi_test
+
+import (
+	"context"
+	"errors"
+	"github.com/bang-go/steam/steamapi"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if r.URL.Path == "/ISteamLeaderboards/GetLeaderboardEntries/v1/" {
+			_, _ = w.Write([]byte(`{"result":{"result":2}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":{"count":1}}`))
+	}))
+	defer srv.Close()
+	store := steamapi.NewMemoryCacheStore(2)
+	cache := &steamapi.CacheConfig{
+		Store: store,
+		TTLs:  map[string]time.Duration{"IGameServersService/GetAccountList": time.Minute},
+	}
+	client := steamapi.New(&steamapi.Config{ApiKey: "a", Host: srv.URL, Cache: cache})
+	same := steamapi.New(&steamapi.Config{ApiKey: "a", Host: srv.URL, Cache: cache})
+	other := steamapi.New(&steamapi.Config{ApiKey: "b", Host: srv.URL, Cache: cache})
+	ctx := context.Background()
+
+	var out struct {
+		Count int `json:"count"`
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := same.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if hits.Load() != 1 || out.Count != 1 {
+		t.Fatalf("expected one request shared by all callers, got %d", hits.Load())
+	}
+	//默认不同 key 共享响应
+	if err := other.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, nil); err != nil || hits.Load() != 1 {
+		t.Fatalf("expected cached response for another key, got %d: %v", hits.Load(), err)
+	}
+	//PerCredential 时按 key 分别缓存
+	cache.PerCredential = true
+	for i := 0; i < 2; i++ {
+		if err := other.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, nil); err != nil || hits.Load() != 2 {
+			t.Fatalf("expected separate request for another key, got %d: %v", hits.Load(), err)
+		}
+	}
+	cache.PerCredential = false
+
+	//未配置 TTL 的接口、POST 及失败的响应不缓存
+	for i := 0; i < 2; i++ {
+		_ = client.Call(ctx, "IGameServersService", "GetServerList", 1, http.MethodGet, nil, nil)
+		_ = client.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodPost, nil, nil)
+	}
+	if hits.Load() != 6 {
+		t.Fatalf("unexpected request count: %d", hits.Load())
+	}
+	cache.DefaultTTL = time.Minute
+	for i := 0; i < 2; i++ {
+		if err := client.Call(ctx, "ISteamLeaderboards", "GetLeaderboardEntries", 1, http.MethodGet, nil, nil); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if hits.Load() != 8 {
+		t.Fatalf("unexpected request count: %d", hits.Load())
+	}
+
+	//参数不同时分别缓存
+	_ = client.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, steamapi.Params{"steamid": 1}, nil)
+	_ = client.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, steamapi.Params{"steamid": 1}, nil)
+	if hits.Load() != 9 {
+		t.Fatalf("unexpected request count: %d", hits.Load())
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	store := steamapi.NewMemoryCacheStore(2)
+	store.Set("a", []byte("1"), time.Minute)
+	store.Set("b", []byte("2"), time.Minute)
+	store.Get("a")
+	store.Set("c", []byte("3"), time.Minute)
+	if _, ok := store.Get("b"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if v, ok := store.Get("a"); !ok || string(v) != "1" {
+		t.Fatal("expected a to be kept")
+	}
+	store.Set("d", []byte("4"), -time.Second)
+	if _, ok := store.Get("d"); ok {
+		t.Fatal("expected expired entry to be missed")
+	}
+}
+
+func TestCacheSingleflightCancel(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"response":{"count":1}}`))
+	}))
+	defer srv.Close()
+	client := steamapi.New(&steamapi.Config{ApiKey: "a", Host: srv.URL, Cache: &steamapi.CacheConfig{DefaultTTL: time.Minute}})
+
+	//第一个调用方超时只影响自己，合并等待的调用方仍可获得响应
+	first := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		first <- client.Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, nil)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	var out struct {
+		Count int `json:"count"`
+	}
+	if err := client.Call(context.Background(), "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, &out); err != nil || out.Count != 1 {
+		t.Fatalf("unexpected result: %+v, %v", out, err)
+	}
+	if err := <-first; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("expected one request, got %d", hits.Load())
+	}
+}
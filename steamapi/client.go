@@ -0,0 +1,294 @@
+package steamapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/bang-go/network/httpx"
+	"github.com/bang-go/steam/steamid"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:generate go run ../cmd/steamapi-gen -in apilist.json -out api_generated.go
+
+const (
+	// HostPublic 公开 Web API 地址
+	HostPublic = "https://api.steampowered.com"
+	// HostPartner 发行商 Web API 地址，发行商接口(如 ISteamLeaderboards)只能通过该地址访问
+	HostPartner = "https://partner.steam-api.com"
+)
+
+const (
+	ParamKey                  = "key"                    //Web API 密钥参数名
+	ParamAccessToken          = "access_token"           //使用 access token 认证时的参数名
+	ParamInputJson            = "input_json"             //以json形式提交参数时使用的参数名
+	ParamInputProtobufEncoded = "input_protobuf_encoded" //以base64编码的protobuf形式提交参数时使用的参数名
+)
+
+const (
+	// EResultOK 请求成功时 Steam 返回的 EResult
+	EResultOK = 1
+)
+
+// Params 以键值对形式提交的请求参数。
+// 切片和数组会展开为 name[0]、name[1]... 的形式(如 server_ips[0])，steamid.SteamID 会转换为64位id。
+type Params map[string]any
+
+// Error Web API 调用失败时返回的错误
+type Error struct {
+	Interface  string
+	Method     string
+	StatusCode int    //http状态码
+	EResult    int    //响应头 x-eresult 或 result 字段中的 EResult，未知时为0
+	Message    string //响应头 x-error_message 中的错误信息
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s/%s 调用失败，status: %d", e.Interface, e.Method, e.StatusCode)
+	if e.EResult != 0 {
+		msg += fmt.Sprintf(", eresult: %d", e.EResult)
+	}
+	if e.Message != "" {
+		msg += ", message: " + e.Message
+	}
+	return msg
+}
+
+type Client interface {
+	// Call 调用任意 Web API 接口，例如 Call(ctx, "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, &out)。
+	// params 为 Params 时按键值对提交，为 proto.Message 时编码后通过 input_protobuf_encoded 提交，为其他非空值时序列化为 json 并通过 input_json 提交。
+	// out 为 proto.Message 时按二进制 protobuf 解析响应，否则去除响应中的 response/result 外层后按 json 解析到 out，out 为空时不解析响应内容。
+	Call(ctx context.Context, iface string, method string, version int, httpMethod string, params any, out any) error
+}
+
+type Config struct {
+	ApiKey      string
+	Host        string //默认 HostPublic
+	Timeout     time.Duration
+	TokenSource TokenSource  //可选，设置后使用 access_token 代替 key 认证
+	Cache       *CacheConfig //可选，缓存只读调用的响应
+	Middlewares []Middleware //可选，按顺序包装每次调用，第一个在最外层
+	//可选，设置后为每次调用记录 OpenTelemetry span 及耗时指标
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+type clientEntity struct {
+	*Config
+	httpClient httpx.Client
+	group      singleflight.Group
+	handler    Handler
+}
+
+func New(cfg *Config) Client {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Host == "" {
+		cfg.Host = HostPublic
+	}
+	if cfg.Cache != nil && cfg.Cache.Store == nil {
+		cfg.Cache.Store = NewMemoryCacheStore(DefaultCacheCapacity)
+	}
+	c := &clientEntity{Config: cfg, httpClient: httpx.New(&httpx.Config{Timeout: cfg.Timeout})}
+	middlewares := cfg.Middlewares
+	if cfg.TracerProvider != nil || cfg.MeterProvider != nil {
+		middlewares = append([]Middleware{telemetryMiddleware(cfg.TracerProvider, cfg.MeterProvider)}, middlewares...)
+	}
+	c.handler = Chain(middlewares...)(c.sendCached)
+	return c
+}
+
+func (s *clientEntity) Call(ctx context.Context, iface string, method string, version int, httpMethod string, params any, out any) (err error) {
+	values, err := encodeParams(params)
+	if err != nil {
+		return
+	}
+	if err = s.authenticate(ctx, values); err != nil {
+		return
+	}
+	req := &Request{Interface: iface, Method: method, Version: version, HttpMethod: httpMethod, Params: values, Headers: map[string]string{}}
+	resp, err := s.handler(ctx, req)
+	if err != nil {
+		return
+	}
+	if len(resp.Content) == 0 {
+		return
+	}
+	return decodeResponse(req, resp, out)
+}
+
+// 发送请求并检查响应，传输层错误中的 key 和 access_token 会被隐藏
+func (s *clientEntity) send(ctx context.Context, req *Request) (resp *Response, err error) {
+	httpReq := &httpx.Request{
+		Method:      req.HttpMethod,
+		Url:         fmt.Sprintf("%s/%s/%s/v%d/", strings.TrimSuffix(s.Host, "/"), req.Interface, req.Method, req.Version),
+		Headers:     req.Headers,
+		ContentType: httpx.ContentForm,
+	}
+	values := make(map[string]string, len(req.Params))
+	for name, value := range req.Params {
+		values[name] = value
+	}
+	if req.HttpMethod == http.MethodGet {
+		httpReq.Params = values
+	} else { //密钥和令牌放在query中，其余参数放在form中
+		httpReq.Params = map[string]string{}
+		for _, name := range []string{ParamKey, ParamAccessToken} {
+			if v, ok := values[name]; ok {
+				httpReq.Params[name] = v
+				delete(values, name)
+			}
+		}
+		httpReq.Body = httpx.FormatFormData(values)
+	}
+	httpResp, err := s.httpClient.Send(ctx, httpReq)
+	if err != nil {
+		err = redactError(err)
+		return
+	}
+	resp = &Response{StatusCode: httpResp.StatusCode, Headers: httpResp.Headers, Content: httpResp.Content}
+	err = checkResponse(req, resp)
+	return
+}
+
+// 设置了 TokenSource 时注入 access_token，否则注入 key，调用方已传入时不覆盖
+func (s *clientEntity) authenticate(ctx context.Context, values map[string]string) (err error) {
+	if _, ok := values[ParamAccessToken]; ok {
+		return
+	}
+	if _, ok := values[ParamKey]; ok {
+		return
+	}
+	if s.TokenSource != nil {
+		values[ParamAccessToken], err = s.TokenSource.Token(ctx)
+		return
+	}
+	if s.ApiKey != "" {
+		values[ParamKey] = s.ApiKey
+	}
+	return
+}
+
+// 将参数转换为键值对，proto.Message 通过 input_protobuf_encoded 提交，其他非 Params 类型的参数通过 input_json 提交
+func encodeParams(params any) (values map[string]string, err error) {
+	values = map[string]string{}
+	switch p := params.(type) {
+	case nil:
+	case Params:
+		for name, value := range p {
+			if err = encodeParam(values, name, value); err != nil {
+				return
+			}
+		}
+	case proto.Message:
+		var data []byte
+		if data, err = proto.Marshal(p); err != nil {
+			return
+		}
+		values[ParamInputProtobufEncoded] = base64.StdEncoding.EncodeToString(data)
+	default:
+		var data []byte
+		if data, err = json.Marshal(p); err != nil {
+			return
+		}
+		values[ParamInputJson] = string(data)
+	}
+	return
+}
+
+func encodeParam(values map[string]string, name string, value any) (err error) {
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8) || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			if err = encodeParam(values, fmt.Sprintf("%s[%d]", name, i), rv.Index(i).Interface()); err != nil {
+				return
+			}
+		}
+		return
+	}
+	values[name], err = formatParam(value)
+	if err != nil {
+		err = fmt.Errorf("参数 %s 格式异常: %w", name, err)
+	}
+	return
+}
+
+func formatParam(value any) (str string, err error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case steamid.SteamID:
+		return strconv.FormatInt(v.RenderSteamID64(), 10), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		str = strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		str = strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		str = strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+	case reflect.String:
+		str = rv.String()
+	case reflect.Bool:
+		str = strconv.FormatBool(rv.Bool())
+	default:
+		err = fmt.Errorf("不支持的参数类型: %T", value)
+	}
+	return
+}
+
+// 检查http状态码及响应头中的 x-eresult
+func checkResponse(req *Request, resp *Response) (err error) {
+	apiErr := &Error{Interface: req.Interface, Method: req.Method, StatusCode: resp.StatusCode, Message: resp.Headers["X-Error_message"]}
+	if v, ok := resp.Headers["X-Eresult"]; ok {
+		apiErr.EResult, _ = strconv.Atoi(v)
+	}
+	if resp.StatusCode != http.StatusOK || (apiErr.EResult != 0 && apiErr.EResult != EResultOK) {
+		err = apiErr
+	}
+	return
+}
+
+// 去除 response/result 外层后解析响应，发行商接口 result 中的 result 字段不为 EResultOK 时返回错误
+func decodeResponse(req *Request, resp *Response, out any) (err error) {
+	if msg, ok := out.(proto.Message); ok {
+		return proto.Unmarshal(resp.Content, msg)
+	}
+	content := resp.Content
+	var envelope map[string]json.RawMessage
+	if json.Unmarshal(content, &envelope) == nil && len(envelope) == 1 {
+		if inner, ok := envelope["response"]; ok {
+			content = inner
+		} else if inner, ok = envelope["result"]; ok {
+			content = inner
+			var result struct {
+				Result *int `json:"result"`
+			}
+			if json.Unmarshal(inner, &result) == nil && result.Result != nil && *result.Result != EResultOK {
+				err = &Error{Interface: req.Interface, Method: req.Method, StatusCode: resp.StatusCode, EResult: *result.Result}
+				return
+			}
+		}
+	}
+	if out == nil {
+		return
+	}
+	return json.Unmarshal(content, out)
+}
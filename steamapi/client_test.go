@@ -0,0 +1,91 @@
+package steamapi_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/bang-go/steam/steamapi"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case "/IGameServersService/GetServerSteamIDsByIP/v1/":
+			if r.Form.Get("key") != "k" || r.Form.Get("server_ips[1]") != "2.2.2.2:27015" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"response":{"servers":[{"steamid":"90000000000000001","addr":"2.2.2.2:27015"}]}}`))
+		case "/IPlayerService/GetOwnedGames/v1/":
+			if r.Form.Get("input_json") != `{"steamid":"1","include_appinfo":true}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"response":{"game_count":3}}`))
+		case "/IAuthenticationService/GetPasswordRSAPublicKey/v1/":
+			data, _ := base64.StdEncoding.DecodeString(r.Form.Get("input_protobuf_encoded"))
+			req := &steampb.CAuthentication_GetPasswordRSAPublicKey_Request{}
+			if proto.Unmarshal(data, req) != nil || req.GetAccountName() != "bot" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ = proto.Marshal(&steampb.CAuthentication_GetPasswordRSAPublicKey_Response{PublickeyMod: proto.String("abcd"), Timestamp: proto.Uint64(7)})
+			_, _ = w.Write(data)
+		case "/ISteamLeaderboards/ResetLeaderboard/v1/":
+			_, _ = w.Write([]byte(`{"result":{"result":8}}`))
+		default:
+			w.Header().Set("X-eresult", "2")
+			w.Header().Set("X-error_message", "fail")
+		}
+	}))
+	defer srv.Close()
+	client := steamapi.New(&steamapi.Config{ApiKey: "k", Host: srv.URL})
+
+	g := steamapi.NewGameServersService(&steamapi.GameServersServiceConfig{Client: client})
+	resp, err := g.GetServerSteamIDsByIP([]string{"1.1.1.1:27015", "2.2.2.2:27015"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Response.Servers) != 1 || resp.Response.Servers[0].Addr != "2.2.2.2:27015" {
+		t.Fatalf("unexpected servers: %+v", resp.Response.Servers)
+	}
+
+	var owned struct {
+		GameCount int `json:"game_count"`
+	}
+	input := struct {
+		SteamId        string `json:"steamid"`
+		IncludeAppInfo bool   `json:"include_appinfo"`
+	}{"1", true}
+	if err = client.Call(context.Background(), "IPlayerService", "GetOwnedGames", 1, http.MethodGet, input, &owned); err != nil {
+		t.Fatal(err)
+	}
+	if owned.GameCount != 3 {
+		t.Fatalf("unexpected game_count: %d", owned.GameCount)
+	}
+
+	rsa := &steampb.CAuthentication_GetPasswordRSAPublicKey_Response{}
+	req := &steampb.CAuthentication_GetPasswordRSAPublicKey_Request{AccountName: proto.String("bot")}
+	if err = client.Call(context.Background(), "IAuthenticationService", "GetPasswordRSAPublicKey", 1, http.MethodGet, req, rsa); err != nil {
+		t.Fatal(err)
+	}
+	if rsa.GetPublickeyMod() != "abcd" || rsa.GetTimestamp() != 7 {
+		t.Fatalf("unexpected rsa key: %v", rsa)
+	}
+
+	var apiErr *steamapi.Error
+	err = steamapi.NewLeaderboardsService(&steamapi.LeaderboardsServiceConfig{Client: client}).ResetLeaderboard(480, 1)
+	if !errors.As(err, &apiErr) || apiErr.EResult != 8 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = client.Call(context.Background(), "IUnknown", "Unknown", 1, http.MethodPost, steamapi.Params{"a": 1}, nil)
+	if !errors.As(err, &apiErr) || apiErr.EResult != 2 || apiErr.Message != "fail" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package steamapi
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/bang-go/steam/steamid"
+	"net/http"
+	"time"
+)
+
+// ISteamLeaderboards 属于发行商接口，需要使用发行商 Web API 密钥，并且只能通过 partner.steam-api.com 访问。
+const (
+	InterfaceLeaderboards = "ISteamLeaderboards"
+)
+
+const (
+	// MaxLeaderboardDetailsSize 分数附加详情的最大字节数
+	MaxLeaderboardDetailsSize = 256
+)
+
+// LeaderboardSortMethod 排行榜排序方式
+type LeaderboardSortMethod string
+
+const (
+	LeaderboardSortAscending  LeaderboardSortMethod = "Ascending"  //分数越低排名越高
+	LeaderboardSortDescending LeaderboardSortMethod = "Descending" //分数越高排名越高
+)
+
+// LeaderboardDisplayType 排行榜分数展示方式
+type LeaderboardDisplayType string
+
+const (
+	LeaderboardDisplayNumeric          LeaderboardDisplayType = "Numeric"          //数值
+	LeaderboardDisplayTimeSeconds      LeaderboardDisplayType = "TimeSeconds"      //以秒为单位的时间
+	LeaderboardDisplayTimeMilliSeconds LeaderboardDisplayType = "TimeMilliSeconds" //以毫秒为单位的时间
+)
+
+// LeaderboardScoreMethod 分数写入方式
+type LeaderboardScoreMethod string
+
+const (
+	LeaderboardScoreKeepBest    LeaderboardScoreMethod = "KeepBest"    //仅在新分数更好时更新
+	LeaderboardScoreForceUpdate LeaderboardScoreMethod = "ForceUpdate" //总是覆盖旧分数
+)
+
+// LeaderboardDataRequest 条目查询类型
+type LeaderboardDataRequest string
+
+const (
+	LeaderboardRequestGlobal     LeaderboardDataRequest = "RequestGlobal"     //按全局排名区间查询
+	LeaderboardRequestAroundUser LeaderboardDataRequest = "RequestAroundUser" //以用户为中心按相对区间查询
+	LeaderboardRequestFriends    LeaderboardDataRequest = "RequestFriends"    //查询用户及其好友
+)
+
+type Leaderboard struct {
+	LeaderboardId     int                    `json:"leaderBoardID"`
+	LeaderboardName   string                 `json:"leaderboardName"`
+	OnlyTrustedWrites bool                   `json:"onlytrustedwrites"`
+	OnlyFriendsReads  bool                   `json:"onlyfriendsreads"`
+	Entries           int                    `json:"entries"`
+	SortMethod        LeaderboardSortMethod  `json:"sortmethod"`
+	DisplayType       LeaderboardDisplayType `json:"displaytype"`
+}
+
+// FindOrCreateLeaderboardOptions 创建排行榜时使用的参数，仅在排行榜不存在且 CreateIfNotFound 为 true 时生效
+type FindOrCreateLeaderboardOptions struct {
+	SortMethod        LeaderboardSortMethod
+	DisplayType       LeaderboardDisplayType
+	CreateIfNotFound  bool
+	OnlyTrustedWrites bool
+	OnlyFriendsReads  bool
+}
+
+type FindOrCreateLeaderboardResp struct {
+	Result struct {
+		Result      int         `json:"result"`
+		Leaderboard Leaderboard `json:"leaderboard"`
+	} `json:"result"`
+}
+
+type SetLeaderboardScoreResp struct {
+	Result struct {
+		Result                int  `json:"result"`
+		LeaderboardEntryCount int  `json:"leaderboard_entry_count"`
+		ScoreChanged          bool `json:"score_changed"`
+		GlobalRankPrevious    int  `json:"global_rank_previous"`
+		GlobalRankNew         int  `json:"global_rank_new"`
+	} `json:"result"`
+}
+
+type LeaderboardEntry struct {
+	SteamId steamid.SteamID
+	Score   int
+	Rank    int
+	UgcId   string //关联的 UGC，没有时为 -1
+	Details []byte //写入分数时附带的详情
+}
+
+type GetLeaderboardEntriesResp struct {
+	AppId                      int
+	LeaderboardId              int
+	TotalLeaderboardEntryCount int
+	Entries                    []LeaderboardEntry
+}
+
+// Steam 原始返回结构，steamID 与 detailData 需要转换
+type leaderboardEntriesRawResp struct {
+	LeaderboardEntryInformation struct {
+		AppId                      int `json:"appID"`
+		LeaderboardId              int `json:"leaderboardID"`
+		TotalLeaderboardEntryCount int `json:"totalLeaderBoardEntryCount"`
+		LeaderboardEntries         []struct {
+			SteamId    string `json:"steamID"`
+			Score      int    `json:"score"`
+			Rank       int    `json:"rank"`
+			UgcId      string `json:"ugcid"`
+			DetailData string `json:"detailData"`
+		} `json:"leaderboardEntries"`
+	} `json:"leaderboardEntryInformation"`
+}
+
+type LeaderboardsService interface {
+	FindOrCreateLeaderboard(appId int, name string, opts *FindOrCreateLeaderboardOptions) (*FindOrCreateLeaderboardResp, error)
+	SetLeaderboardScore(appId int, leaderboardId int, sid steamid.SteamID, score int, scoreMethod LeaderboardScoreMethod, details []byte) (*SetLeaderboardScoreResp, error)
+	GetLeaderboardEntries(appId int, leaderboardId int, rangeStart int, rangeEnd int) (*GetLeaderboardEntriesResp, error)                                //按全局排名区间查询，例如 1~10
+	GetLeaderboardEntriesAroundUser(appId int, leaderboardId int, sid steamid.SteamID, rangeStart int, rangeEnd int) (*GetLeaderboardEntriesResp, error) //以用户为中心查询，例如 -5~5
+	GetLeaderboardEntriesForFriends(appId int, leaderboardId int, sid steamid.SteamID) (*GetLeaderboardEntriesResp, error)
+	ResetLeaderboard(appId int, leaderboardId int) error
+	DeleteLeaderboard(appId int, name string) error
+}
+
+type LeaderboardsServiceConfig struct {
+	ApiKey  string //发行商 Web API 密钥
+	Timeout time.Duration
+	Client  Client //可选，为空时使用 ApiKey 和 Timeout 创建，Host 需为 HostPartner
+}
+type leaderboardsServiceEntity struct {
+	*LeaderboardsServiceConfig
+}
+
+func NewLeaderboardsService(cfg *LeaderboardsServiceConfig) LeaderboardsService {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{ApiKey: cfg.ApiKey, Host: HostPartner, Timeout: cfg.Timeout})
+	}
+	return &leaderboardsServiceEntity{LeaderboardsServiceConfig: cfg}
+}
+
+func (s *leaderboardsServiceEntity) FindOrCreateLeaderboard(appId int, name string, opts *FindOrCreateLeaderboardOptions) (resp *FindOrCreateLeaderboardResp, err error) {
+	if opts == nil {
+		opts = &FindOrCreateLeaderboardOptions{}
+	}
+	params := Params{
+		"appid":             appId,
+		"name":              name,
+		"createifnotfound":  opts.CreateIfNotFound,
+		"onlytrustedwrites": opts.OnlyTrustedWrites,
+		"onlyfriendsreads":  opts.OnlyFriendsReads,
+	}
+	if opts.SortMethod != "" {
+		params["sortmethod"] = opts.SortMethod
+	}
+	if opts.DisplayType != "" {
+		params["displaytype"] = opts.DisplayType
+	}
+	resp = &FindOrCreateLeaderboardResp{}
+	if err = s.Client.Call(context.Background(), InterfaceLeaderboards, "FindOrCreateLeaderboard", 2, http.MethodPost, params, &resp.Result); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *leaderboardsServiceEntity) SetLeaderboardScore(appId int, leaderboardId int, sid steamid.SteamID, score int, scoreMethod LeaderboardScoreMethod, details []byte) (resp *SetLeaderboardScoreResp, err error) {
+	if len(details) > MaxLeaderboardDetailsSize {
+		err = fmt.Errorf("details长度超出最大限制,len:%d", len(details))
+		return
+	}
+	if scoreMethod == "" {
+		scoreMethod = LeaderboardScoreKeepBest
+	}
+	params := Params{
+		"appid":         appId,
+		"leaderboardid": leaderboardId,
+		"steamid":       sid,
+		"score":         score,
+		"scoremethod":   scoreMethod,
+	}
+	if len(details) > 0 {
+		params["details"] = hex.EncodeToString(details)
+	}
+	resp = &SetLeaderboardScoreResp{}
+	if err = s.Client.Call(context.Background(), InterfaceLeaderboards, "SetLeaderboardScore", 1, http.MethodPost, params, &resp.Result); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (s *leaderboardsServiceEntity) GetLeaderboardEntries(appId int, leaderboardId int, rangeStart int, rangeEnd int) (*GetLeaderboardEntriesResp, error) {
+	return s.getLeaderboardEntries(appId, leaderboardId, LeaderboardRequestGlobal, nil, rangeStart, rangeEnd)
+}
+
+func (s *leaderboardsServiceEntity) GetLeaderboardEntriesAroundUser(appId int, leaderboardId int, sid steamid.SteamID, rangeStart int, rangeEnd int) (*GetLeaderboardEntriesResp, error) {
+	return s.getLeaderboardEntries(appId, leaderboardId, LeaderboardRequestAroundUser, sid, rangeStart, rangeEnd)
+}
+
+func (s *leaderboardsServiceEntity) GetLeaderboardEntriesForFriends(appId int, leaderboardId int, sid steamid.SteamID) (*GetLeaderboardEntriesResp, error) {
+	return s.getLeaderboardEntries(appId, leaderboardId, LeaderboardRequestFriends, sid, 0, 0)
+}
+
+func (s *leaderboardsServiceEntity) getLeaderboardEntries(appId int, leaderboardId int, dataRequest LeaderboardDataRequest, sid steamid.SteamID, rangeStart int, rangeEnd int) (resp *GetLeaderboardEntriesResp, err error) {
+	params := Params{
+		"appid":         appId,
+		"leaderboardid": leaderboardId,
+		"datarequest":   dataRequest,
+		"rangestart":    rangeStart,
+		"rangeend":      rangeEnd,
+	}
+	if sid != nil {
+		params["steamid"] = sid
+	}
+	var raw leaderboardEntriesRawResp
+	if err = s.Client.Call(context.Background(), InterfaceLeaderboards, "GetLeaderboardEntries", 1, http.MethodGet, params, &raw); err != nil {
+		return
+	}
+	return parseLeaderboardEntries(&raw)
+}
+
+func (s *leaderboardsServiceEntity) ResetLeaderboard(appId int, leaderboardId int) (err error) {
+	return s.Client.Call(context.Background(), InterfaceLeaderboards, "ResetLeaderboard", 1, http.MethodPost, Params{"appid": appId, "leaderboardid": leaderboardId}, nil)
+}
+
+func (s *leaderboardsServiceEntity) DeleteLeaderboard(appId int, name string) (err error) {
+	return s.Client.Call(context.Background(), InterfaceLeaderboards, "DeleteLeaderboard", 1, http.MethodPost, Params{"appid": appId, "name": name}, nil)
+}
+
+// 将原始条目中的 steamID 与 detailData 转换为对应类型
+func parseLeaderboardEntries(raw *leaderboardEntriesRawResp) (resp *GetLeaderboardEntriesResp, err error) {
+	info := raw.LeaderboardEntryInformation
+	resp = &GetLeaderboardEntriesResp{
+		AppId:                      info.AppId,
+		LeaderboardId:              info.LeaderboardId,
+		TotalLeaderboardEntryCount: info.TotalLeaderboardEntryCount,
+		Entries:                    make([]LeaderboardEntry, 0, len(info.LeaderboardEntries)),
+	}
+	for _, e := range info.LeaderboardEntries {
+		entry := LeaderboardEntry{Score: e.Score, Rank: e.Rank, UgcId: e.UgcId}
+		if entry.SteamId, err = steamid.New(e.SteamId); err != nil {
+			err = fmt.Errorf("异常的steamID: %s, %w", e.SteamId, err)
+			return
+		}
+		if entry.Details, err = hex.DecodeString(e.DetailData); err != nil {
+			err = fmt.Errorf("异常的detailData: %s, %w", e.DetailData, err)
+			return
+		}
+		resp.Entries = append(resp.Entries, entry)
+	}
+	return
+}
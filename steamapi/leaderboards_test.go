@@ -0,0 +1,95 @@
+package steamapi_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	"github.com/bang-go/steam/steamid"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestLeaderboards(t *testing.T) {
+	var mu sync.Mutex
+	var form url.Values
+	details, steamId := "", "76561197960287930"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = r.ParseForm()
+		form = r.Form
+		switch r.URL.Path {
+		case "/ISteamLeaderboards/FindOrCreateLeaderboard/v2/":
+			if r.PostForm.Get("name") == "missing" {
+				_, _ = w.Write([]byte(`{"result":{"result":8}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"result":{"result":1,"leaderboard":{"leaderBoardID":5,"leaderboardName":"score","entries":2,"sortmethod":"Descending","displaytype":"Numeric"}}}`))
+		case "/ISteamLeaderboards/SetLeaderboardScore/v1/":
+			details = r.PostForm.Get("details")
+			_, _ = w.Write([]byte(`{"result":{"result":1,"leaderboard_entry_count":2,"score_changed":true,"global_rank_new":1}}`))
+		case "/ISteamLeaderboards/GetLeaderboardEntries/v1/":
+			_, _ = fmt.Fprintf(w, `{"leaderboardEntryInformation":{"appID":480,"leaderboardID":5,"totalLeaderBoardEntryCount":1,"leaderboardEntries":[{"steamID":"%s","score":100,"rank":1,"ugcid":"-1","detailData":"%s"}]}}`, steamId, details)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	lb := steamapi.NewLeaderboardsService(&steamapi.LeaderboardsServiceConfig{Client: steamapi.New(&steamapi.Config{ApiKey: "secret", Host: srv.URL})})
+
+	resp, err := lb.FindOrCreateLeaderboard(480, "score", &steamapi.FindOrCreateLeaderboardOptions{
+		SortMethod:       steamapi.LeaderboardSortDescending,
+		DisplayType:      steamapi.LeaderboardDisplayNumeric,
+		CreateIfNotFound: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result.Leaderboard.LeaderboardId != 5 || resp.Result.Leaderboard.SortMethod != steamapi.LeaderboardSortDescending {
+		t.Fatalf("unexpected response: %+v", resp.Result)
+	}
+	want := map[string]string{"key": "secret", "appid": "480", "name": "score", "createifnotfound": "true", "onlytrustedwrites": "false", "sortmethod": "Descending", "displaytype": "Numeric"}
+	for name, value := range want {
+		if form.Get(name) != value {
+			t.Fatalf("unexpected param %s: %q, form: %v", name, form.Get(name), form)
+		}
+	}
+
+	//details 以十六进制提交，读取条目时还原
+	sid, _ := steamid.New(steamId)
+	data := []byte{0x00, 0x01, 0xab, 0xff}
+	score, err := lb.SetLeaderboardScore(480, 5, sid, 100, "", data)
+	if err != nil || !score.Result.ScoreChanged {
+		t.Fatalf("unexpected response: %+v, %v", score, err)
+	}
+	if form.Get("details") != "0001abff" || form.Get("scoremethod") != string(steamapi.LeaderboardScoreKeepBest) || form.Get("steamid") != steamId {
+		t.Fatalf("unexpected params: %v", form)
+	}
+	entries, err := lb.GetLeaderboardEntriesAroundUser(480, 5, sid, -5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("datarequest") != string(steamapi.LeaderboardRequestAroundUser) || form.Get("rangestart") != "-5" {
+		t.Fatalf("unexpected params: %v", form)
+	}
+	if len(entries.Entries) != 1 || entries.Entries[0].SteamId.RenderSteamID64() != sid.RenderSteamID64() || string(entries.Entries[0].Details) != string(data) {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if _, err = lb.SetLeaderboardScore(480, 5, sid, 100, "", make([]byte, steamapi.MaxLeaderboardDetailsSize+1)); err == nil {
+		t.Fatal("expected error for oversized details")
+	}
+
+	steamId = "not-a-steamid"
+	if _, err = lb.GetLeaderboardEntries(480, 5, 1, 10); err == nil {
+		t.Fatal("expected error for invalid steamID")
+	}
+
+	//result 不为 OK 时返回 Error
+	var apiErr *steamapi.Error
+	if _, err = lb.FindOrCreateLeaderboard(480, "missing", nil); !errors.As(err, &apiErr) || apiErr.EResult != 8 {
+		t.Fatalf("expected steamapi.Error with eresult 8, got %v", err)
+	}
+}
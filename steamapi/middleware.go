@@ -0,0 +1,115 @@
+package steamapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+const (
+	RedactedValue = "[REDACTED]" //隐藏敏感参数后的值
+)
+
+// 需要隐藏的参数
+var sensitiveParams = []string{ParamKey, ParamAccessToken}
+
+// Request 中间件看到的请求，Params 已完成编码及认证参数(key 或 access_token)注入，中间件可以修改
+type Request struct {
+	Interface  string
+	Method     string
+	Version    int
+	HttpMethod string
+	Params     map[string]string
+	Headers    map[string]string
+}
+
+// Response 中间件看到的响应，出错时可能为空
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Content    []byte
+	Cached     bool //响应来自缓存
+}
+
+// Handler 发送请求并返回响应
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware 包装 Handler，可在调用前后执行自定义逻辑，如日志、指标、添加请求头、故障注入
+type Middleware func(next Handler) Handler
+
+// Chain 将多个中间件组合为一个，第一个在最外层
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// RedactParams 返回隐藏了 key 和 access_token 的参数副本，用于日志等输出
+func RedactParams(params map[string]string) map[string]string {
+	redacted := make(map[string]string, len(params))
+	for name, value := range params {
+		redacted[name] = value
+	}
+	for _, name := range sensitiveParams {
+		if _, ok := redacted[name]; ok {
+			redacted[name] = RedactedValue
+		}
+	}
+	return redacted
+}
+
+// 传输层错误(*url.Error)中包含完整的请求地址，隐藏其中的敏感参数
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	u, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		return err
+	}
+	query := u.Query()
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Set(name, RedactedValue)
+		}
+	}
+	u.RawQuery = query.Encode()
+	urlErr.URL = u.String()
+	return err
+}
+
+// LoggingMiddleware 使用 slog 记录每次调用，参数中的 key 和 access_token 会被隐藏，logger 为空时使用 slog.Default()
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (resp *Response, err error) {
+			start := time.Now()
+			resp, err = next(ctx, req)
+			attrs := []slog.Attr{
+				slog.String("interface", req.Interface),
+				slog.String("method", req.Method),
+				slog.Int("version", req.Version),
+				slog.String("http_method", req.HttpMethod),
+				slog.Any("params", RedactParams(req.Params)),
+				slog.Duration("latency", time.Since(start)),
+			}
+			if resp != nil {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.Bool("cached", resp.Cached))
+			}
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "steamapi call failed", append(attrs, slog.String("error", err.Error()))...)
+			} else {
+				logger.LogAttrs(ctx, slog.LevelInfo, "steamapi call", attrs...)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package steamapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/bang-go/steam/steamapi"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "1" || r.URL.Query().Get("key") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":{}}`))
+	}))
+	defer srv.Close()
+
+	var order []string
+	trace := func(name string) steamapi.Middleware {
+		return func(next steamapi.Handler) steamapi.Handler {
+			return func(ctx context.Context, req *steamapi.Request) (*steamapi.Response, error) {
+				order = append(order, name)
+				req.Headers["X-Trace"] = "1"
+				return next(ctx, req)
+			}
+		}
+	}
+	errFault := errors.New("fault")
+	fault := func(next steamapi.Handler) steamapi.Handler {
+		return func(ctx context.Context, req *steamapi.Request) (*steamapi.Response, error) {
+			if req.Method == "Fail" {
+				return nil, errFault
+			}
+			return next(ctx, req)
+		}
+	}
+	var logs bytes.Buffer
+	client := steamapi.New(&steamapi.Config{
+		ApiKey: "secret",
+		Host:   srv.URL,
+		Middlewares: []steamapi.Middleware{
+			steamapi.LoggingMiddleware(slog.New(slog.NewTextHandler(&logs, nil))),
+			trace("a"),
+			trace("b"),
+			fault,
+		},
+	})
+	ctx := context.Background()
+	if err := client.Call(ctx, "ISteamUser", "GetPlayerSummaries", 2, http.MethodGet, steamapi.Params{"steamids": "1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "a,b" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	if err := client.Call(ctx, "ISteamUser", "Fail", 1, http.MethodGet, nil, nil); !errors.Is(err, errFault) {
+		t.Fatalf("expected fault, got %v", err)
+	}
+	out := logs.String()
+	if strings.Contains(out, "secret") || !strings.Contains(out, steamapi.RedactedValue) || !strings.Contains(out, "GetPlayerSummaries") || !strings.Contains(out, "level=ERROR") {
+		t.Fatalf("unexpected logs: %s", out)
+	}
+
+	srv.Close()
+	err := client.Call(ctx, "ISteamUser", "GetPlayerSummaries", 2, http.MethodGet, nil, nil)
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected redacted transport error, got %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+// Package steampb Steam protobuf 消息定义，包括 Web API 中只支持 protobuf 编码的接口及加密应用票据使用的消息。
+package steampb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative auth.proto encrypted_app_ticket.proto
@@ -0,0 +1,92 @@
+package steamapi
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+	"strconv"
+	"time"
+)
+
+const (
+	instrumentationName = "github.com/bang-go/steam/steamapi"
+	MetricCallDuration  = "steamapi.call.duration" //调用耗时(秒)
+)
+
+// 链路及指标的属性名
+const (
+	AttrInterface  = attribute.Key("steam.interface")
+	AttrMethod     = attribute.Key("steam.method")
+	AttrVersion    = attribute.Key("steam.version")
+	AttrEResult    = attribute.Key("steam.eresult")
+	AttrCached     = attribute.Key("steam.cached")
+	AttrHttpMethod = attribute.Key("http.request.method")
+	AttrStatusCode = attribute.Key("http.response.status_code")
+)
+
+// 为每次调用记录 span 及耗时指标，只记录接口名和结果，不记录参数(key、access_token)
+func telemetryMiddleware(tp trace.TracerProvider, mp metric.MeterProvider) Middleware {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	tracer := tp.Tracer(instrumentationName)
+	duration, err := mp.Meter(instrumentationName).Float64Histogram(MetricCallDuration, metric.WithUnit("s"), metric.WithDescription("Steam Web API 调用耗时"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (resp *Response, err error) {
+			attrs := []attribute.KeyValue{
+				AttrInterface.String(req.Interface),
+				AttrMethod.String(req.Method),
+				AttrVersion.Int(req.Version),
+				AttrHttpMethod.String(req.HttpMethod),
+			}
+			ctx, span := tracer.Start(ctx, "steamapi "+req.Interface+"/"+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			defer span.End()
+			start := time.Now()
+			resp, err = next(ctx, req)
+			var result []attribute.KeyValue //调用结束后才能确定的属性
+			if resp != nil {
+				result = append(result, AttrStatusCode.Int(resp.StatusCode))
+				span.SetAttributes(AttrCached.Bool(resp.Cached))
+			}
+			if eresult := responseEResult(resp, err); eresult != 0 {
+				result = append(result, AttrEResult.Int(eresult))
+			}
+			span.SetAttributes(result...)
+			attrs = append(attrs, result...)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			if duration != nil {
+				duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+			}
+			return
+		}
+	}
+}
+
+func responseEResult(resp *Response, err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.EResult != 0 {
+		return apiErr.EResult
+	}
+	if resp != nil {
+		if v, ok := resp.Headers["X-Eresult"]; ok {
+			eresult, _ := strconv.Atoi(v)
+			return eresult
+		}
+	}
+	return 0
+}
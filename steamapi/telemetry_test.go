@@ -0,0 +1,67 @@
+package steamapi_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTelemetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-eresult", "15")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	client := steamapi.New(&steamapi.Config{
+		ApiKey:         "secret",
+		Host:           srv.URL,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err := client.Call(context.Background(), "IGameServersService", "GetAccountList", 1, http.MethodGet, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 || ended[0].Name() != "steamapi IGameServersService/GetAccountList" {
+		t.Fatalf("unexpected spans: %v", ended)
+	}
+	attrs := map[string]string{}
+	for _, kv := range ended[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["steam.eresult"] != "15" || attrs["http.response.status_code"] != "403" || attrs["steam.method"] != "GetAccountList" {
+		t.Fatalf("unexpected attributes: %v", attrs)
+	}
+	if strings.Contains(fmt.Sprint(ended[0].Attributes(), ended[0].Events()), "secret") {
+		t.Fatal("api key recorded in span")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var count uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if h, ok := m.Data.(metricdata.Histogram[float64]); ok && m.Name == steamapi.MetricCallDuration {
+				for _, dp := range h.DataPoints {
+					count += dp.Count
+				}
+			}
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected one recorded call, got %d", count)
+	}
+}
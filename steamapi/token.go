@@ -0,0 +1,179 @@
+package steamapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"github.com/bang-go/steam/steamid"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRenewBefore = 5 * time.Minute //access token 过期前多久开始续期
+)
+
+// TokenClaims Steam 令牌(JWT)中的声明，Steam 未公开签名公钥，此处不校验签名
+type TokenClaims struct {
+	Issuer    string
+	SteamId   steamid.SteamID
+	Audience  []string //如 web、renew、derive、client
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	Id        string
+}
+
+// HasAudience 是否包含指定的 audience，refresh token 包含 renew
+func (c *TokenClaims) HasAudience(aud string) bool {
+	for _, a := range c.Audience {
+		if a == aud {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenClaimsRaw struct {
+	Iss string          `json:"iss"`
+	Sub string          `json:"sub"`
+	Aud json.RawMessage `json:"aud"` //字符串或字符串数组
+	Exp int64           `json:"exp"`
+	Nbf int64           `json:"nbf"`
+	Iat int64           `json:"iat"`
+	Jti string          `json:"jti"`
+}
+
+// ParseToken 解析 access token 或 refresh token 中的声明
+func ParseToken(token string) (claims *TokenClaims, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = errors.New("异常的token格式")
+		return
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return
+	}
+	var raw tokenClaimsRaw
+	if err = json.Unmarshal(payload, &raw); err != nil {
+		return
+	}
+	claims = &TokenClaims{
+		Issuer:    raw.Iss,
+		ExpiresAt: time.Unix(raw.Exp, 0),
+		NotBefore: time.Unix(raw.Nbf, 0),
+		IssuedAt:  time.Unix(raw.Iat, 0),
+		Id:        raw.Jti,
+	}
+	if len(raw.Aud) > 0 {
+		if err = json.Unmarshal(raw.Aud, &claims.Audience); err != nil {
+			var aud string
+			if err = json.Unmarshal(raw.Aud, &aud); err != nil {
+				return
+			}
+			claims.Audience = []string{aud}
+		}
+	}
+	if claims.SteamId, err = steamid.New(raw.Sub); err != nil {
+		err = fmt.Errorf("异常的sub: %s, %w", raw.Sub, err)
+	}
+	return
+}
+
+// TokenSource 为 Client 提供 access token，设置后调用时使用 access_token 代替 key
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// RefreshTokenSource 使用 refresh token 通过 IAuthenticationService/GenerateAccessTokenForApp 自动续期 access token
+type RefreshTokenSource interface {
+	TokenSource
+	// RefreshToken 当前 refresh token，开启 AllowRenewal 后可能被 Steam 更换，需要自行持久化
+	RefreshToken() string
+}
+
+type RefreshTokenSourceConfig struct {
+	RefreshToken string
+	AccessToken  string        //可选，初始 access token
+	Client       Client        //续期使用，不能设置 TokenSource，为空时使用默认配置创建
+	RenewBefore  time.Duration //默认 DefaultRenewBefore
+	AllowRenewal bool          //允许 Steam 在续期时同时更换 refresh token
+}
+
+type refreshTokenSourceEntity struct {
+	*RefreshTokenSourceConfig
+	mu        sync.Mutex
+	expiresAt time.Time
+}
+
+func NewRefreshTokenSource(cfg *RefreshTokenSourceConfig) RefreshTokenSource {
+	if cfg.Client == nil {
+		cfg.Client = New(&Config{})
+	}
+	if cfg.RenewBefore == 0 {
+		cfg.RenewBefore = DefaultRenewBefore
+	}
+	s := &refreshTokenSourceEntity{RefreshTokenSourceConfig: cfg}
+	if claims, err := ParseToken(cfg.AccessToken); err == nil {
+		s.expiresAt = claims.ExpiresAt
+	}
+	return s
+}
+
+func (s *refreshTokenSourceEntity) Token(ctx context.Context) (token string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.AccessToken != "" && time.Until(s.expiresAt) > s.RenewBefore {
+		return s.AccessToken, nil
+	}
+	if err = s.renew(ctx); err != nil {
+		return
+	}
+	return s.AccessToken, nil
+}
+
+func (s *refreshTokenSourceEntity) RefreshToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.RefreshTokenSourceConfig.RefreshToken
+}
+
+func (s *refreshTokenSourceEntity) renew(ctx context.Context) (err error) {
+	claims, err := ParseToken(s.RefreshTokenSourceConfig.RefreshToken)
+	if err != nil {
+		return
+	}
+	if time.Now().After(claims.ExpiresAt) {
+		return fmt.Errorf("refresh token已过期,expires:%s", claims.ExpiresAt)
+	}
+	renewal := steampb.ETokenRenewalType_k_ETokenRenewalType_None
+	if s.AllowRenewal {
+		renewal = steampb.ETokenRenewalType_k_ETokenRenewalType_Allow
+	}
+	req := &steampb.CAuthentication_AccessToken_GenerateForApp_Request{
+		RefreshToken: proto.String(s.RefreshTokenSourceConfig.RefreshToken),
+		Steamid:      proto.Uint64(uint64(claims.SteamId.RenderSteamID64())),
+		RenewalType:  renewal.Enum(),
+	}
+	resp := &steampb.CAuthentication_AccessToken_GenerateForApp_Response{}
+	if err = s.Client.Call(ctx, InterfaceAuthentication, "GenerateAccessTokenForApp", 1, http.MethodPost, req, resp); err != nil {
+		return
+	}
+	accessClaims, err := ParseToken(resp.GetAccessToken())
+	if err != nil {
+		return
+	}
+	s.AccessToken = resp.GetAccessToken()
+	s.expiresAt = accessClaims.ExpiresAt
+	if resp.GetRefreshToken() != "" {
+		s.RefreshTokenSourceConfig.RefreshToken = resp.GetRefreshToken()
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package steamapi_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	"github.com/bang-go/steam/steamapi/steampb"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// 构造未签名的 Steam 风格 JWT
+func testToken(aud string, expires time.Time) string {
+	payload := fmt.Sprintf(`{"iss":"steam","sub":"76561199181487706","aud":%s,"exp":%d,"nbf":0,"iat":0,"jti":"id"}`, aud, expires.Unix())
+	return "eyJhbGciOiJFZERTQSJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	refresh := testToken(`["web","renew","derive"]`, time.Now().Add(24*time.Hour))
+	claims, err := steamapi.ParseToken(refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.SteamId.RenderSteamID64() != 76561199181487706 || !claims.HasAudience("renew") {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	renewed := 0
+	access := testToken(`"web"`, time.Now().Add(time.Hour))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		switch r.URL.Path {
+		case "/IAuthenticationService/GenerateAccessTokenForApp/v1/":
+			input, _ := base64.StdEncoding.DecodeString(r.Form.Get("input_protobuf_encoded"))
+			req := &steampb.CAuthentication_AccessToken_GenerateForApp_Request{}
+			_ = proto.Unmarshal(input, req)
+			if req.GetRefreshToken() != refresh || req.GetSteamid() != 76561199181487706 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			renewed++
+			data, _ := proto.Marshal(&steampb.CAuthentication_AccessToken_GenerateForApp_Response{AccessToken: proto.String(access)})
+			_, _ = w.Write(data)
+		default:
+			if r.URL.Query().Get("access_token") != access || r.URL.Query().Get("key") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"response":{}}`))
+		}
+	}))
+	defer srv.Close()
+
+	source := steamapi.NewRefreshTokenSource(&steamapi.RefreshTokenSourceConfig{
+		RefreshToken: refresh,
+		AccessToken:  testToken(`"web"`, time.Now().Add(time.Minute)), //即将过期，需要续期
+		Client:       steamapi.New(&steamapi.Config{Host: srv.URL}),
+	})
+	client := steamapi.New(&steamapi.Config{ApiKey: "k", Host: srv.URL, TokenSource: source})
+	for i := 0; i < 2; i++ {
+		if err = client.Call(context.Background(), "IPlayerService", "GetOwnedGames", 1, http.MethodPost, steamapi.Params{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if renewed != 1 {
+		t.Fatalf("unexpected renew count: %d", renewed)
+	}
+}
@@ -0,0 +1,316 @@
+// Package steamcommunity 解析 Steam 社区的个人资料及组成员 XML(?xml=1)，无需 Web API 密钥。
+package steamcommunity
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/bang-go/network/httpx"
+	"github.com/bang-go/steam/steamid"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HostCommunity 社区地址
+	HostCommunity     = "https://steamcommunity.com"
+	DefaultReqTimeout = 10 * time.Second
+)
+
+// PrivacyState 个人资料隐私状态
+type PrivacyState string
+
+const (
+	PrivacyPublic      PrivacyState = "public"
+	PrivacyFriendsOnly PrivacyState = "friendsonly"
+	PrivacyPrivate     PrivacyState = "private"
+)
+
+// ErrNotFound 个人资料或组不存在
+var ErrNotFound = errors.New("个人资料或组不存在")
+
+type ProfileGroup struct {
+	GroupId   steamid.SteamID
+	IsPrimary bool
+	Name      string
+	Url       string //组的自定义地址，即 GroupMembers 的 groupUrl
+}
+
+type Profile struct {
+	SteamId          steamid.SteamID
+	Name             string
+	OnlineState      string //online、offline、in-game
+	StateMessage     string
+	PrivacyState     PrivacyState
+	VisibilityState  int //1:私密 3:公开
+	AvatarIcon       string
+	AvatarMedium     string
+	AvatarFull       string
+	VacBanned        bool
+	TradeBanState    string //None、Probation、Banned
+	IsLimitedAccount bool
+	CustomUrl        string
+	MemberSince      string //本地化的注册日期，如 "September 12, 2003"，仅公开资料
+	HoursPlayed2Wk   float64
+	Headline         string
+	Location         string
+	RealName         string
+	Summary          string
+	Groups           []ProfileGroup
+}
+
+type GroupDetails struct {
+	Name          string
+	Url           string
+	Headline      string
+	Summary       string
+	AvatarIcon    string
+	AvatarMedium  string
+	AvatarFull    string
+	MemberCount   int
+	MembersInChat int
+	MembersInGame int
+	MembersOnline int
+}
+
+// GroupMembersPage 组成员列表的一页，每页最多1000个成员
+type GroupMembersPage struct {
+	GroupId        steamid.SteamID
+	Details        GroupDetails
+	MemberCount    int
+	TotalPages     int
+	CurrentPage    int
+	StartingMember int
+	Members        []steamid.SteamID
+}
+
+type Community interface {
+	// Profile 通过 SteamID 查询个人资料，私密资料只返回部分字段
+	Profile(ctx context.Context, sid steamid.SteamID) (*Profile, error)
+	// ProfileByCustomUrl 通过自定义地址(steamcommunity.com/id/<customUrl>)查询个人资料
+	ProfileByCustomUrl(ctx context.Context, customUrl string) (*Profile, error)
+	// GroupMembers 通过组地址(steamcommunity.com/groups/<groupUrl>)查询成员列表，page 从1开始
+	GroupMembers(ctx context.Context, groupUrl string, page int) (*GroupMembersPage, error)
+	// GroupMembersByGid 通过组 ID 查询成员列表，page 从1开始
+	GroupMembersByGid(ctx context.Context, gid steamid.SteamID, page int) (*GroupMembersPage, error)
+	// AllGroupMembers 依次查询所有分页，返回全部成员
+	AllGroupMembers(ctx context.Context, groupUrl string) ([]steamid.SteamID, error)
+}
+
+type Config struct {
+	Host    string //默认 HostCommunity
+	Timeout time.Duration
+}
+
+type communityEntity struct {
+	*Config
+	httpClient httpx.Client
+}
+
+func New(cfg *Config) Community {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Host == "" {
+		cfg.Host = HostCommunity
+	}
+	return &communityEntity{Config: cfg, httpClient: httpx.New(&httpx.Config{Timeout: cfg.Timeout})}
+}
+
+type profileGroupXml struct {
+	IsPrimary bool   `xml:"isPrimary,attr"`
+	GroupId64 string `xml:"groupID64"`
+	Name      string `xml:"groupName"`
+	Url       string `xml:"groupURL"`
+}
+
+type profileXml struct {
+	Error            string            `xml:"error"`
+	SteamId64        string            `xml:"steamID64"`
+	Name             string            `xml:"steamID"`
+	OnlineState      string            `xml:"onlineState"`
+	StateMessage     string            `xml:"stateMessage"`
+	PrivacyState     string            `xml:"privacyState"`
+	VisibilityState  int               `xml:"visibilityState"`
+	AvatarIcon       string            `xml:"avatarIcon"`
+	AvatarMedium     string            `xml:"avatarMedium"`
+	AvatarFull       string            `xml:"avatarFull"`
+	VacBanned        bool              `xml:"vacBanned"`
+	TradeBanState    string            `xml:"tradeBanState"`
+	IsLimitedAccount bool              `xml:"isLimitedAccount"`
+	CustomUrl        string            `xml:"customURL"`
+	MemberSince      string            `xml:"memberSince"`
+	HoursPlayed2Wk   float64           `xml:"hoursPlayed2Wk"`
+	Headline         string            `xml:"headline"`
+	Location         string            `xml:"location"`
+	RealName         string            `xml:"realname"`
+	Summary          string            `xml:"summary"`
+	Groups           []profileGroupXml `xml:"groups>group"`
+}
+
+type memberListXml struct {
+	Error     string `xml:"error"`
+	GroupId64 string `xml:"groupID64"`
+	Details   struct {
+		Name          string `xml:"groupName"`
+		Url           string `xml:"groupURL"`
+		Headline      string `xml:"headline"`
+		Summary       string `xml:"summary"`
+		AvatarIcon    string `xml:"avatarIcon"`
+		AvatarMedium  string `xml:"avatarMedium"`
+		AvatarFull    string `xml:"avatarFull"`
+		MemberCount   int    `xml:"memberCount"`
+		MembersInChat int    `xml:"membersInChat"`
+		MembersInGame int    `xml:"membersInGame"`
+		MembersOnline int    `xml:"membersOnline"`
+	} `xml:"groupDetails"`
+	MemberCount    int      `xml:"memberCount"`
+	TotalPages     int      `xml:"totalPages"`
+	CurrentPage    int      `xml:"currentPage"`
+	StartingMember int      `xml:"startingMember"`
+	Members        []string `xml:"members>steamID64"`
+}
+
+func (c *communityEntity) Profile(ctx context.Context, sid steamid.SteamID) (*Profile, error) {
+	return c.profile(ctx, "/profiles/"+strconv.FormatInt(sid.RenderSteamID64(), 10))
+}
+
+func (c *communityEntity) ProfileByCustomUrl(ctx context.Context, customUrl string) (*Profile, error) {
+	return c.profile(ctx, "/id/"+url.PathEscape(customUrl))
+}
+
+func (c *communityEntity) profile(ctx context.Context, path string) (profile *Profile, err error) {
+	var raw profileXml
+	if err = c.get(ctx, path, nil, &raw); err != nil {
+		return
+	}
+	if raw.Error != "" {
+		err = fmt.Errorf("%w: %s", ErrNotFound, raw.Error)
+		return
+	}
+	profile = &Profile{
+		Name:             raw.Name,
+		OnlineState:      raw.OnlineState,
+		StateMessage:     raw.StateMessage,
+		PrivacyState:     PrivacyState(raw.PrivacyState),
+		VisibilityState:  raw.VisibilityState,
+		AvatarIcon:       raw.AvatarIcon,
+		AvatarMedium:     raw.AvatarMedium,
+		AvatarFull:       raw.AvatarFull,
+		VacBanned:        raw.VacBanned,
+		TradeBanState:    raw.TradeBanState,
+		IsLimitedAccount: raw.IsLimitedAccount,
+		CustomUrl:        raw.CustomUrl,
+		MemberSince:      raw.MemberSince,
+		HoursPlayed2Wk:   raw.HoursPlayed2Wk,
+		Headline:         raw.Headline,
+		Location:         raw.Location,
+		RealName:         raw.RealName,
+		Summary:          raw.Summary,
+	}
+	if profile.SteamId, err = steamid.New(raw.SteamId64); err != nil {
+		err = fmt.Errorf("异常的steamID64: %s, %w", raw.SteamId64, err)
+		return
+	}
+	for _, g := range raw.Groups {
+		group := ProfileGroup{IsPrimary: g.IsPrimary, Name: g.Name, Url: g.Url}
+		if group.GroupId, err = parseGid(g.GroupId64); err != nil {
+			return
+		}
+		profile.Groups = append(profile.Groups, group)
+	}
+	return
+}
+
+func (c *communityEntity) GroupMembers(ctx context.Context, groupUrl string, page int) (*GroupMembersPage, error) {
+	return c.groupMembers(ctx, "/groups/"+url.PathEscape(groupUrl)+"/memberslistxml/", page)
+}
+
+func (c *communityEntity) GroupMembersByGid(ctx context.Context, gid steamid.SteamID, page int) (*GroupMembersPage, error) {
+	return c.groupMembers(ctx, "/gid/"+strconv.FormatInt(gid.RenderSteamID64(), 10)+"/memberslistxml/", page)
+}
+
+func (c *communityEntity) AllGroupMembers(ctx context.Context, groupUrl string) (members []steamid.SteamID, err error) {
+	for page := 1; ; page++ {
+		var p *GroupMembersPage
+		if p, err = c.GroupMembers(ctx, groupUrl, page); err != nil {
+			return
+		}
+		members = append(members, p.Members...)
+		if page >= p.TotalPages || len(p.Members) == 0 {
+			return
+		}
+	}
+}
+
+func (c *communityEntity) groupMembers(ctx context.Context, path string, page int) (result *GroupMembersPage, err error) {
+	if page < 1 {
+		page = 1
+	}
+	var raw memberListXml
+	if err = c.get(ctx, path, map[string]string{"p": strconv.Itoa(page)}, &raw); err != nil {
+		return
+	}
+	if raw.Error != "" {
+		err = fmt.Errorf("%w: %s", ErrNotFound, raw.Error)
+		return
+	}
+	result = &GroupMembersPage{
+		Details:        GroupDetails(raw.Details),
+		MemberCount:    raw.MemberCount,
+		TotalPages:     raw.TotalPages,
+		CurrentPage:    raw.CurrentPage,
+		StartingMember: raw.StartingMember,
+		Members:        make([]steamid.SteamID, 0, len(raw.Members)),
+	}
+	if result.GroupId, err = parseGid(raw.GroupId64); err != nil {
+		return
+	}
+	for _, m := range raw.Members {
+		var sid steamid.SteamID
+		if sid, err = steamid.New(strings.TrimSpace(m)); err != nil {
+			err = fmt.Errorf("异常的成员steamID64: %s, %w", m, err)
+			return
+		}
+		result.Members = append(result.Members, sid)
+	}
+	return
+}
+
+// 组 ID 为 BaseGID + 组的 accountid
+func parseGid(raw string) (gid steamid.SteamID, err error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id < steamid.BaseGID {
+		err = fmt.Errorf("异常的groupID64: %s", raw)
+		return
+	}
+	return steamid.New(strconv.FormatUint(id, 10))
+}
+
+func (c *communityEntity) get(ctx context.Context, path string, params map[string]string, out any) (err error) {
+	if params == nil {
+		params = map[string]string{}
+	}
+	params["xml"] = "1"
+	httpResp, err := c.httpClient.Send(ctx, &httpx.Request{
+		Method:      http.MethodGet,
+		Url:         strings.TrimSuffix(c.Host, "/") + path,
+		Params:      params,
+		ContentType: httpx.ContentForm,
+	})
+	if err != nil {
+		return
+	}
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
+	}
+	return xml.Unmarshal(httpResp.Content, out)
+}
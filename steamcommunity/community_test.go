@@ -0,0 +1,107 @@
+package steamcommunity_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/steamcommunity"
+	"github.com/bang-go/steam/steamid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const profileXml = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<profile>
+	<steamID64>76561199181487706</steamID64>
+	<steamID><![CDATA[bang]]></steamID>
+	<onlineState>in-game</onlineState>
+	<privacyState>public</privacyState>
+	<visibilityState>3</visibilityState>
+	<vacBanned>1</vacBanned>
+	<tradeBanState>None</tradeBanState>
+	<isLimitedAccount>0</isLimitedAccount>
+	<customURL><![CDATA[bang]]></customURL>
+	<hoursPlayed2Wk>12.5</hoursPlayed2Wk>
+	<headline></headline>
+	<groups>
+		<group isPrimary="1">
+			<groupID64>103582791429521412</groupID64>
+			<groupName><![CDATA[Valve]]></groupName>
+			<groupURL><![CDATA[Valve]]></groupURL>
+		</group>
+	</groups>
+</profile>`
+
+func TestProfile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("xml") != "1" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/profiles/76561199181487706", "/id/bang":
+			_, _ = w.Write([]byte(profileXml))
+		default:
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><response><error><![CDATA[The specified profile could not be found.]]></error></response>`))
+		}
+	}))
+	defer srv.Close()
+	community := steamcommunity.New(&steamcommunity.Config{Host: srv.URL})
+	ctx := context.Background()
+
+	sid, _ := steamid.New("76561199181487706")
+	profile, err := community.Profile(ctx, sid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.SteamId.RenderSteamID64() != 76561199181487706 || profile.Name != "bang" || profile.PrivacyState != steamcommunity.PrivacyPublic {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	if !profile.VacBanned || profile.IsLimitedAccount || profile.HoursPlayed2Wk != 12.5 || profile.VisibilityState != 3 {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	if len(profile.Groups) != 1 || !profile.Groups[0].IsPrimary || profile.Groups[0].GroupId.GetAccountID() != 4 {
+		t.Fatalf("unexpected groups: %+v", profile.Groups)
+	}
+	if _, err = community.ProfileByCustomUrl(ctx, "bang"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = community.ProfileByCustomUrl(ctx, "missing"); !errors.Is(err, steamcommunity.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGroupMembers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/groups/Valve/memberslistxml/" && r.URL.Path != "/gid/103582791429521412/memberslistxml/" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		page := r.URL.Query().Get("p")
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0"?><memberList>
+<groupID64>103582791429521412</groupID64>
+<groupDetails><groupName><![CDATA[Valve]]></groupName><groupURL><![CDATA[Valve]]></groupURL><memberCount>3</memberCount><membersOnline>1</membersOnline></groupDetails>
+<memberCount>3</memberCount><totalPages>2</totalPages><currentPage>%s</currentPage><startingMember>0</startingMember>
+<members><steamID64>7656119918148770%s</steamID64>%s</members></memberList>`, page, page, map[string]string{"1": "<steamID64>76561197960287930</steamID64>"}[page])
+	}))
+	defer srv.Close()
+	community := steamcommunity.New(&steamcommunity.Config{Host: srv.URL})
+	ctx := context.Background()
+
+	page, err := community.GroupMembers(ctx, "Valve", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.GroupId.RenderSteamID64() != 103582791429521412 || page.Details.Name != "Valve" || page.Details.MembersOnline != 1 || page.CurrentPage != 2 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if _, err = community.GroupMembersByGid(ctx, page.GroupId, 1); err != nil {
+		t.Fatal(err)
+	}
+	members, err := community.AllGroupMembers(ctx, "Valve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 3 || members[2].RenderSteamID64() != 76561199181487702 {
+		t.Fatalf("unexpected members: %v", members)
+	}
+}
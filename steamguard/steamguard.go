@@ -0,0 +1,152 @@
+// Package steamguard 生成 Steam 令牌(Steam Guard)验证码及移动端确认密钥。
+package steamguard
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	InterfaceTwoFactor = "ITwoFactorService"
+)
+
+const (
+	CodeLength   = 5                            //验证码长度
+	CodePeriod   = 30                           //验证码有效周期(秒)
+	codeAlphabet = "23456789BCDFGHJKMNPQRTVWXY" //验证码字符集
+	maxTagLength = 32                           //确认密钥中 tag 的最大长度
+)
+
+// 常用的确认 tag
+const (
+	TagConfirmationList = "conf"    //获取确认列表
+	TagDetails          = "details" //获取确认详情
+	TagAllow            = "allow"   //同意确认
+	TagCancel           = "cancel"  //拒绝确认
+)
+
+type SteamGuard interface {
+	// AuthCode 生成当前(已校正时间偏移)的验证码
+	AuthCode() (string, error)
+	// ConfirmationKey 生成当前(已校正时间偏移)指定 tag 的确认密钥，返回密钥及使用的时间戳
+	ConfirmationKey(tag string) (key string, timestamp int64, err error)
+	// SyncTime 通过 ITwoFactorService/QueryTime 获取 Steam 服务器时间并更新时间偏移
+	SyncTime(ctx context.Context) error
+	// Offset 当前时间偏移(服务器时间 - 本地时间)
+	Offset() time.Duration
+}
+
+type Config struct {
+	SharedSecret   string          //base64 编码的 shared_secret
+	IdentitySecret string          //base64 编码的 identity_secret
+	Client         steamapi.Client //SyncTime 使用，可选，为空时使用默认配置创建
+}
+
+type steamGuardEntity struct {
+	*Config
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+func New(cfg *Config) SteamGuard {
+	if cfg.Client == nil {
+		cfg.Client = steamapi.New(&steamapi.Config{})
+	}
+	return &steamGuardEntity{Config: cfg}
+}
+
+func (s *steamGuardEntity) AuthCode() (string, error) {
+	return GenerateAuthCode(s.SharedSecret, s.now())
+}
+
+func (s *steamGuardEntity) ConfirmationKey(tag string) (key string, timestamp int64, err error) {
+	now := s.now()
+	key, err = GenerateConfirmationKey(s.IdentitySecret, now, tag)
+	timestamp = now.Unix()
+	return
+}
+
+func (s *steamGuardEntity) SyncTime(ctx context.Context) (err error) {
+	offset, err := QueryTimeOffset(ctx, s.Client)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.offset = offset
+	s.mu.Unlock()
+	return
+}
+
+func (s *steamGuardEntity) Offset() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.offset
+}
+
+func (s *steamGuardEntity) now() time.Time {
+	return time.Now().Add(s.Offset())
+}
+
+// GenerateAuthCode 根据 shared_secret 生成指定时间的5位验证码
+func GenerateAuthCode(sharedSecret string, t time.Time) (code string, err error) {
+	secret, err := base64.StdEncoding.DecodeString(sharedSecret)
+	if err != nil {
+		return
+	}
+	mac := hmac.New(sha1.New, secret)
+	_ = binary.Write(mac, binary.BigEndian, uint64(t.Unix()/CodePeriod))
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0F
+	fullCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7FFFFFFF
+	buf := make([]byte, CodeLength)
+	for i := range buf {
+		buf[i] = codeAlphabet[fullCode%uint32(len(codeAlphabet))]
+		fullCode /= uint32(len(codeAlphabet))
+	}
+	code = string(buf)
+	return
+}
+
+// GenerateConfirmationKey 根据 identity_secret 生成指定时间和 tag 的确认密钥(base64)
+func GenerateConfirmationKey(identitySecret string, t time.Time, tag string) (key string, err error) {
+	secret, err := base64.StdEncoding.DecodeString(identitySecret)
+	if err != nil {
+		return
+	}
+	if len(tag) > maxTagLength {
+		tag = tag[:maxTagLength]
+	}
+	mac := hmac.New(sha1.New, secret)
+	_ = binary.Write(mac, binary.BigEndian, uint64(t.Unix()))
+	mac.Write([]byte(tag))
+	key = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return
+}
+
+type queryTimeResp struct {
+	ServerTime json.Number `json:"server_time"`
+}
+
+// QueryTimeOffset 通过 ITwoFactorService/QueryTime 计算 Steam 服务器时间与本地时间的偏移
+func QueryTimeOffset(ctx context.Context, client steamapi.Client) (offset time.Duration, err error) {
+	var resp queryTimeResp
+	if err = client.Call(ctx, InterfaceTwoFactor, "QueryTime", 1, http.MethodPost, steamapi.Params{"steamid": "0"}, &resp); err != nil {
+		return
+	}
+	serverTime, err := resp.ServerTime.Int64()
+	if err != nil {
+		err = fmt.Errorf("异常的server_time: %s", resp.ServerTime)
+		return
+	}
+	offset = time.Duration(serverTime-time.Now().Unix()) * time.Second
+	return
+}
@@ -0,0 +1,54 @@
+package steamguard_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/bang-go/steam/steamapi"
+	"github.com/bang-go/steam/steamguard"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSteamGuard(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	code, err := steamguard.GenerateAuthCode("c2hhcmVkLXNlY3JldC1mb3ItdGVzdHM=", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "R57P7" {
+		t.Fatalf("unexpected code: %s", code)
+	}
+	key, err := steamguard.GenerateConfirmationKey("aWRlbnRpdHktc2VjcmV0LWZvci10ZXN0cw==", at, steamguard.TagConfirmationList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "4+X2qnz/DVG446SYfp3W6AQZ71s=" {
+		t.Fatalf("unexpected key: %s", key)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"response":{"server_time":"%d","skew_tolerance_seconds":"60"}}`, time.Now().Add(time.Hour).Unix())
+	}))
+	defer srv.Close()
+	g := steamguard.New(&steamguard.Config{
+		SharedSecret: "c2hhcmVkLXNlY3JldC1mb3ItdGVzdHM=",
+		Client:       steamapi.New(&steamapi.Config{Host: srv.URL}),
+	})
+	if err = g.SyncTime(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if offset := g.Offset(); offset < time.Hour-2*time.Second || offset > time.Hour+2*time.Second {
+		t.Fatalf("unexpected offset: %s", offset)
+	}
+	before, _ := steamguard.GenerateAuthCode("c2hhcmVkLXNlY3JldC1mb3ItdGVzdHM=", time.Now().Add(g.Offset()))
+	code, err = g.AuthCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := steamguard.GenerateAuthCode("c2hhcmVkLXNlY3JldC1mb3ItdGVzdHM=", time.Now().Add(g.Offset()))
+	if code != before && code != after { //跨越验证码周期时两者不同
+		t.Fatalf("unexpected code: %s", code)
+	}
+}
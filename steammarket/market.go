@@ -0,0 +1,314 @@
+// Package steammarket Steam 社区市场价格接口(steamcommunity.com/market)。
+package steammarket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bang-go/network/httpx"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HostCommunity 社区地址
+	HostCommunity     = "https://steamcommunity.com"
+	DefaultReqTimeout = 10 * time.Second
+	// DefaultInterval 默认请求间隔，社区接口限流严格，约每分钟20次
+	DefaultInterval = 3 * time.Second
+)
+
+const (
+	UrlPriceOverview = "/market/priceoverview/"
+	UrlPriceHistory  = "/market/pricehistory/"
+)
+
+// Currency Steam 钱包货币代码
+type Currency int
+
+const (
+	CurrencyUSD Currency = 1
+	CurrencyGBP Currency = 2
+	CurrencyEUR Currency = 3
+	CurrencyCHF Currency = 4
+	CurrencyRUB Currency = 5
+	CurrencyPLN Currency = 6
+	CurrencyBRL Currency = 7
+	CurrencyJPY Currency = 8
+	CurrencyKRW Currency = 16
+	CurrencyTRY Currency = 17
+	CurrencyUAH Currency = 18
+	CurrencyCAD Currency = 20
+	CurrencyAUD Currency = 21
+	CurrencyCNY Currency = 23
+)
+
+// ErrRateLimited 被社区接口限流(429)
+var ErrRateLimited = errors.New("请求过于频繁，已被限流")
+
+// 价格历史中的时间格式，如 "Jul 02 2014 01: +0"，时区固定为 UTC
+const historyTimeLayout = "Jan 02 2006 15"
+
+type PriceOverview struct {
+	LowestPrice decimal.Decimal //当前最低售价
+	MedianPrice decimal.Decimal //24小时成交中位价
+	Volume      int             //24小时成交量
+	HasLowest   bool            //没有在售时 Steam 不返回最低售价
+	HasMedian   bool            //没有成交时 Steam 不返回中位价
+}
+
+type PricePoint struct {
+	Time   time.Time //整点时间(UTC)
+	Price  decimal.Decimal
+	Volume int
+}
+
+type PriceHistory struct {
+	PricePrefix string //货币前缀，如 $
+	PriceSuffix string //货币后缀，如 €
+	Prices      []PricePoint
+}
+
+type Market interface {
+	// PriceOverview 查询物品当前价格
+	PriceOverview(ctx context.Context, currency Currency, appId int, marketHashName string) (*PriceOverview, error)
+	// PriceHistory 查询物品历史价格，需要登录 Cookie(Config.LoginSecure)
+	PriceHistory(ctx context.Context, currency Currency, appId int, marketHashName string) (*PriceHistory, error)
+}
+
+type Config struct {
+	Host        string //默认 HostCommunity
+	Timeout     time.Duration
+	LoginSecure string        //steamLoginSecure cookie，PriceHistory 必填
+	Interval    time.Duration //两次请求的最小间隔，默认 DefaultInterval，小于0时不限制
+}
+
+type marketEntity struct {
+	*Config
+	httpClient httpx.Client
+	limiter    *limiter
+}
+
+func New(cfg *Config) Market {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Host == "" {
+		cfg.Host = HostCommunity
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = DefaultInterval
+	}
+	return &marketEntity{
+		Config:     cfg,
+		httpClient: httpx.New(&httpx.Config{Timeout: cfg.Timeout}),
+		limiter:    &limiter{interval: cfg.Interval},
+	}
+}
+
+type priceOverviewResp struct {
+	Success     bool   `json:"success"`
+	LowestPrice string `json:"lowest_price"`
+	MedianPrice string `json:"median_price"`
+	Volume      string `json:"volume"`
+}
+
+func (m *marketEntity) PriceOverview(ctx context.Context, currency Currency, appId int, marketHashName string) (overview *PriceOverview, err error) {
+	var resp priceOverviewResp
+	if err = m.get(ctx, UrlPriceOverview, currency, appId, marketHashName, nil, &resp); err != nil {
+		return
+	}
+	if !resp.Success {
+		err = fmt.Errorf("查询价格失败, market_hash_name:%s", marketHashName)
+		return
+	}
+	overview = &PriceOverview{}
+	if resp.LowestPrice != "" {
+		if overview.LowestPrice, err = ParsePrice(resp.LowestPrice); err != nil {
+			return
+		}
+		overview.HasLowest = true
+	}
+	if resp.MedianPrice != "" {
+		if overview.MedianPrice, err = ParsePrice(resp.MedianPrice); err != nil {
+			return
+		}
+		overview.HasMedian = true
+	}
+	if resp.Volume != "" {
+		if overview.Volume, err = parseVolume(resp.Volume); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type priceHistoryResp struct {
+	Success     bool                `json:"success"`
+	PricePrefix string              `json:"price_prefix"`
+	PriceSuffix string              `json:"price_suffix"`
+	Prices      [][]json.RawMessage `json:"prices"` //[时间, 价格, 成交量]
+}
+
+func (m *marketEntity) PriceHistory(ctx context.Context, currency Currency, appId int, marketHashName string) (history *PriceHistory, err error) {
+	if m.LoginSecure == "" {
+		err = errors.New("查询历史价格需要设置LoginSecure")
+		return
+	}
+	var resp priceHistoryResp
+	cookies := map[string]string{"steamLoginSecure": m.LoginSecure}
+	if err = m.get(ctx, UrlPriceHistory, currency, appId, marketHashName, cookies, &resp); err != nil {
+		return
+	}
+	if !resp.Success {
+		err = fmt.Errorf("查询历史价格失败, market_hash_name:%s", marketHashName)
+		return
+	}
+	history = &PriceHistory{PricePrefix: resp.PricePrefix, PriceSuffix: resp.PriceSuffix}
+	history.Prices = make([]PricePoint, 0, len(resp.Prices))
+	for _, raw := range resp.Prices {
+		var point PricePoint
+		if point, err = parsePricePoint(raw); err != nil {
+			return
+		}
+		history.Prices = append(history.Prices, point)
+	}
+	return
+}
+
+func parsePricePoint(raw []json.RawMessage) (point PricePoint, err error) {
+	if len(raw) < 3 {
+		err = fmt.Errorf("异常的历史价格: %s", raw)
+		return
+	}
+	var date, volume string
+	var price json.Number
+	if err = json.Unmarshal(raw[0], &date); err != nil {
+		return
+	}
+	if err = json.Unmarshal(raw[1], &price); err != nil {
+		return
+	}
+	if err = json.Unmarshal(raw[2], &volume); err != nil {
+		return
+	}
+	//去掉末尾的 ": +0"
+	if i := strings.LastIndex(date, ":"); i >= 0 {
+		date = date[:i]
+	}
+	if point.Time, err = time.ParseInLocation(historyTimeLayout, date, time.UTC); err != nil {
+		return
+	}
+	if point.Price, err = decimal.NewFromString(price.String()); err != nil {
+		return
+	}
+	point.Volume, err = parseVolume(volume)
+	return
+}
+
+func (m *marketEntity) get(ctx context.Context, path string, currency Currency, appId int, marketHashName string, cookies map[string]string, out any) (err error) {
+	if err = m.limiter.wait(ctx); err != nil {
+		return
+	}
+	httpResp, err := m.httpClient.Send(ctx, &httpx.Request{
+		Method: http.MethodGet,
+		Url:    strings.TrimSuffix(m.Host, "/") + path,
+		Params: map[string]string{
+			"currency":         strconv.Itoa(int(currency)),
+			"appid":            strconv.Itoa(appId),
+			"market_hash_name": marketHashName,
+		},
+		Cookies:     cookies,
+		ContentType: httpx.ContentForm,
+	})
+	if err != nil {
+		return
+	}
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
+	}
+	return json.Unmarshal(httpResp.Content, out)
+}
+
+// ParsePrice 解析社区返回的带货币符号的价格，如 "$1,234.56"、"1.234,56€"、"¥ 12"
+func ParsePrice(s string) (price decimal.Decimal, err error) {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
+			b.WriteRune(r)
+		}
+	}
+	num := strings.Trim(b.String(), ".,")
+	if num == "" {
+		err = fmt.Errorf("异常的价格: %s", s)
+		return
+	}
+	//最后一个分隔符后为1-2位数字时视为小数点，其余分隔符均为千位分隔符
+	decimalSep := -1
+	if i := strings.LastIndexAny(num, ".,"); i >= 0 && len(num)-i-1 <= 2 {
+		decimalSep = i
+	}
+	b.Reset()
+	for i, r := range num {
+		switch {
+		case i == decimalSep:
+			b.WriteByte('.')
+		case r == '.' || r == ',':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return decimal.NewFromString(b.String())
+}
+
+func parseVolume(s string) (int, error) {
+	return strconv.Atoi(strings.NewReplacer(",", "", ".", "", " ", "").Replace(s))
+}
+
+// 固定间隔的限流器，请求按顺序排队
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		//放弃等待时归还名额，之后没有其他调用方排队时下一个请求不必多等一个间隔
+		l.mu.Lock()
+		if l.next.Equal(at.Add(l.interval)) {
+			l.next = at
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+package steammarket_test
+
+import (
+	"context"
+	"github.com/bang-go/steam/steammarket"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParsePrice(t *testing.T) {
+	cases := map[string]string{
+		"$1,234.56":      "1234.56",
+		"1.234,56€":      "1234.56",
+		"¥ 12":           "12",
+		"0,03€":          "0.03",
+		"1 234,5 pуб.":   "1234.5",
+		"₩ 1,500":        "1500",
+		"R$ 2.000":       "2000",
+		"CDN$ 0.12":      "0.12",
+		"12,--€":         "12",
+		"￥ 1,234,567.00": "1234567",
+	}
+	for in, want := range cases {
+		got, err := steammarket.ParsePrice(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if got.String() != want {
+			t.Fatalf("%s: got %s, want %s", in, got, want)
+		}
+	}
+	if _, err := steammarket.ParsePrice("--"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMarket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("currency") != "1" || q.Get("appid") != "730" || q.Get("market_hash_name") != "AK-47 | Redline (Field-Tested)" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case steammarket.UrlPriceOverview:
+			_, _ = w.Write([]byte(`{"success":true,"lowest_price":"$12.34","volume":"1,234","median_price":"$12.00"}`))
+		case steammarket.UrlPriceHistory:
+			if c, err := r.Cookie("steamLoginSecure"); err != nil || c.Value != "secure" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"price_prefix":"$","price_suffix":"","prices":[["Jul 02 2014 01: +0",417.777,"40"],["Jul 03 2014 13: +0",12.5,"1,001"]]}`))
+		}
+	}))
+	defer srv.Close()
+	market := steammarket.New(&steammarket.Config{Host: srv.URL, LoginSecure: "secure", Interval: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+	overview, err := market.PriceOverview(ctx, steammarket.CurrencyUSD, 730, "AK-47 | Redline (Field-Tested)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.LowestPrice.String() != "12.34" || overview.MedianPrice.String() != "12" || overview.Volume != 1234 || !overview.HasLowest {
+		t.Fatalf("unexpected overview: %+v", overview)
+	}
+
+	history, err := market.PriceHistory(ctx, steammarket.CurrencyUSD, 730, "AK-47 | Redline (Field-Tested)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected requests to be rate limited")
+	}
+	if len(history.Prices) != 2 || history.PricePrefix != "$" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	p := history.Prices[1]
+	if !p.Time.Equal(time.Date(2014, 7, 3, 13, 0, 0, 0, time.UTC)) || p.Price.String() != "12.5" || p.Volume != 1001 {
+		t.Fatalf("unexpected point: %+v", p)
+	}
+
+	if _, err = steammarket.New(&steammarket.Config{Host: srv.URL}).PriceHistory(ctx, steammarket.CurrencyUSD, 730, "x"); err == nil {
+		t.Fatal("expected error without LoginSecure")
+	}
+}
+
+// 等待中取消的请求不占用名额，不会推迟下一个请求
+func TestMarketCancelledWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"lowest_price":"$1.00","volume":"1"}`))
+	}))
+	defer srv.Close()
+	market := steammarket.New(&steammarket.Config{Host: srv.URL, Interval: 200 * time.Millisecond})
+	start := time.Now()
+	if _, err := market.PriceOverview(context.Background(), steammarket.CurrencyUSD, 730, "x"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := market.PriceOverview(ctx, steammarket.CurrencyUSD, 730, "x"); err == nil {
+		t.Fatal("expected error for cancelled wait")
+	}
+	if _, err := market.PriceOverview(context.Background(), steammarket.CurrencyUSD, 730, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed >= 400*time.Millisecond {
+		t.Fatalf("unexpected wait: %v", elapsed)
+	}
+}
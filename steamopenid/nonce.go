@@ -0,0 +1,39 @@
+package steamopenid
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceStore 记录已使用的 response_nonce，防止回调被重放
+type NonceStore interface {
+	// Accept 记录 nonce，nonce 已被使用过时返回 false
+	Accept(nonce string, issuedAt time.Time) bool
+}
+
+type memoryNonceStore struct {
+	mu     sync.Mutex
+	maxAge time.Duration
+	nonces map[string]time.Time
+}
+
+// NewMemoryNonceStore 基于内存的 NonceStore，超过 maxAge 的 nonce 会被清理
+func NewMemoryNonceStore(maxAge time.Duration) NonceStore {
+	return &memoryNonceStore{maxAge: maxAge, nonces: map[string]time.Time{}}
+}
+
+func (s *memoryNonceStore) Accept(nonce string, issuedAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, t := range s.nonces { //过期的 nonce 已无法通过时间校验，不再需要记录
+		if now.Sub(t) > s.maxAge {
+			delete(s.nonces, key)
+		}
+	}
+	if _, ok := s.nonces[nonce]; ok {
+		return false
+	}
+	s.nonces[nonce] = issuedAt
+	return true
+}
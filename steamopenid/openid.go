@@ -0,0 +1,215 @@
+package steamopenid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bang-go/steam/steamid"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ProviderUrl Steam OpenID 2.0 登录地址
+	ProviderUrl = "https://steamcommunity.com/openid/login"
+	// NsOpenId2 OpenID 2.0 命名空间
+	NsOpenId2 = "http://specs.openid.net/auth/2.0"
+	// IdentifierSelect 由 Steam 选择用户身份
+	IdentifierSelect = "http://specs.openid.net/auth/2.0/identifier_select"
+)
+
+const (
+	DefaultReqTimeout  = 10 * time.Second
+	DefaultNonceMaxAge = 5 * time.Minute //回调中 response_nonce 的最长有效时间
+)
+
+// response_nonce 开头的时间格式
+const nonceTimeLayout = "2006-01-02T15:04:05Z"
+
+// claimed_id 格式 https://steamcommunity.com/openid/id/76561199181487706
+var regClaimedId = regexp.MustCompile(`^https://steamcommunity\.com/openid/id/([0-9]+)$`)
+
+// 回调中必须经过签名的字段
+var requiredSignedFields = []string{"op_endpoint", "claimed_id", "identity", "return_to", "response_nonce", "assoc_handle"}
+
+// HttpClient 发送 check_authentication 请求使用的客户端，*http.Client 实现了该接口
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type OpenId interface {
+	// AuthUrl 获取跳转到 Steam 登录页面的地址
+	AuthUrl() string
+	// Verify 校验 Steam 回调的 query 参数，成功时返回登录用户的 SteamID
+	Verify(ctx context.Context, query url.Values) (steamid.SteamID, error)
+}
+
+type Config struct {
+	Realm       string        //站点地址，如 https://admin.example.com
+	ReturnTo    string        //回调地址，需在 Realm 之下，如 https://admin.example.com/auth/steam/callback
+	ProviderUrl string        //默认 ProviderUrl
+	HttpClient  HttpClient    //可选，为空时使用 Timeout 创建 *http.Client
+	NonceStore  NonceStore    //可选，为空时使用内存存储，多实例部署时需要共享存储
+	NonceMaxAge time.Duration //默认 DefaultNonceMaxAge
+	Timeout     time.Duration
+}
+
+type openIdEntity struct {
+	*Config
+}
+
+func New(cfg *Config) OpenId {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.NonceMaxAge == 0 {
+		cfg.NonceMaxAge = DefaultNonceMaxAge
+	}
+	if cfg.ProviderUrl == "" {
+		cfg.ProviderUrl = ProviderUrl
+	}
+	if cfg.HttpClient == nil {
+		cfg.HttpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	if cfg.NonceStore == nil {
+		cfg.NonceStore = NewMemoryNonceStore(cfg.NonceMaxAge)
+	}
+	return &openIdEntity{Config: cfg}
+}
+
+func (s *openIdEntity) AuthUrl() string {
+	values := url.Values{
+		"openid.ns":         {NsOpenId2},
+		"openid.mode":       {"checkid_setup"},
+		"openid.return_to":  {s.ReturnTo},
+		"openid.realm":      {s.Realm},
+		"openid.identity":   {IdentifierSelect},
+		"openid.claimed_id": {IdentifierSelect},
+	}
+	return s.ProviderUrl + "?" + values.Encode()
+}
+
+func (s *openIdEntity) Verify(ctx context.Context, query url.Values) (sid steamid.SteamID, err error) {
+	if mode := query.Get("openid.mode"); mode != "id_res" {
+		err = fmt.Errorf("异常的openid.mode: %s", mode)
+		return
+	}
+	if query.Get("openid.ns") != NsOpenId2 {
+		err = fmt.Errorf("异常的openid.ns: %s", query.Get("openid.ns"))
+		return
+	}
+	if query.Get("openid.op_endpoint") != s.ProviderUrl {
+		err = fmt.Errorf("异常的openid.op_endpoint: %s", query.Get("openid.op_endpoint"))
+		return
+	}
+	if err = s.checkReturnTo(query.Get("openid.return_to")); err != nil {
+		return
+	}
+	if err = checkSigned(query.Get("openid.signed")); err != nil {
+		return
+	}
+	claimedId := query.Get("openid.claimed_id")
+	match := regClaimedId.FindStringSubmatch(claimedId)
+	if match == nil || query.Get("openid.identity") != claimedId {
+		err = fmt.Errorf("异常的openid.claimed_id: %s", claimedId)
+		return
+	}
+	if err = s.checkNonce(query.Get("openid.response_nonce")); err != nil {
+		return
+	}
+	if err = s.checkAuthentication(ctx, query); err != nil {
+		return
+	}
+	return steamid.New(match[1])
+}
+
+// return_to 需要与配置的回调地址一致(忽略query)
+func (s *openIdEntity) checkReturnTo(returnTo string) (err error) {
+	actual, err := url.Parse(returnTo)
+	if err != nil {
+		return
+	}
+	expected, err := url.Parse(s.ReturnTo)
+	if err != nil {
+		return
+	}
+	if actual.Scheme != expected.Scheme || actual.Host != expected.Host || actual.Path != expected.Path {
+		err = fmt.Errorf("openid.return_to不匹配: %s", returnTo)
+	}
+	return
+}
+
+func checkSigned(signed string) (err error) {
+	fields := map[string]bool{}
+	for _, field := range strings.Split(signed, ",") {
+		fields[field] = true
+	}
+	for _, field := range requiredSignedFields {
+		if !fields[field] {
+			err = fmt.Errorf("openid.signed缺少字段: %s", field)
+			return
+		}
+	}
+	return
+}
+
+// response_nonce 格式 2024-01-01T00:00:00Z + 唯一字符串，超时或重复使用时返回错误
+func (s *openIdEntity) checkNonce(nonce string) (err error) {
+	if len(nonce) < len(nonceTimeLayout) {
+		err = fmt.Errorf("异常的openid.response_nonce: %s", nonce)
+		return
+	}
+	issuedAt, err := time.Parse(nonceTimeLayout, nonce[:len(nonceTimeLayout)])
+	if err != nil {
+		err = fmt.Errorf("异常的openid.response_nonce: %s", nonce)
+		return
+	}
+	if age := time.Since(issuedAt); age > s.NonceMaxAge || age < -s.NonceMaxAge {
+		err = fmt.Errorf("openid.response_nonce已过期: %s", nonce)
+		return
+	}
+	if !s.NonceStore.Accept(nonce, issuedAt) {
+		err = fmt.Errorf("openid.response_nonce已被使用: %s", nonce)
+	}
+	return
+}
+
+// 向 Steam 发送 check_authentication 请求校验签名
+func (s *openIdEntity) checkAuthentication(ctx context.Context, query url.Values) (err error) {
+	form := url.Values{}
+	for key, value := range query {
+		if strings.HasPrefix(key, "openid.") {
+			form[key] = value
+		}
+	}
+	form.Set("openid.mode", "check_authentication")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.ProviderUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.HttpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("状态码异常，status: %d", resp.StatusCode)
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	// 响应为 key:value 格式，每行一个
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.TrimSpace(line) == "is_valid:true" {
+			return
+		}
+	}
+	return errors.New("签名校验失败")
+}
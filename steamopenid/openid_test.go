@@ -0,0 +1,66 @@
+package steamopenid_test
+
+import (
+	"context"
+	"github.com/bang-go/steam/steamopenid"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		valid := r.Form.Get("openid.mode") == "check_authentication" && r.Form.Get("openid.sig") == "sig"
+		_, _ = w.Write([]byte("ns:http://specs.openid.net/auth/2.0\nis_valid:" + map[bool]string{true: "true", false: "false"}[valid] + "\n"))
+	}))
+	defer srv.Close()
+	o := steamopenid.New(&steamopenid.Config{
+		Realm:       "https://admin.example.com",
+		ReturnTo:    "https://admin.example.com/auth/steam/callback",
+		ProviderUrl: srv.URL,
+		HttpClient:  srv.Client(),
+	})
+	if authUrl := o.AuthUrl(); !strings.Contains(authUrl, "openid.return_to=https%3A%2F%2Fadmin.example.com%2Fauth%2Fsteam%2Fcallback") {
+		t.Fatalf("unexpected auth url: %s", authUrl)
+	}
+
+	claimedId := "https://steamcommunity.com/openid/id/76561199181487706"
+	query := url.Values{
+		"openid.ns":             {steamopenid.NsOpenId2},
+		"openid.mode":           {"id_res"},
+		"openid.op_endpoint":    {srv.URL},
+		"openid.claimed_id":     {claimedId},
+		"openid.identity":       {claimedId},
+		"openid.return_to":      {"https://admin.example.com/auth/steam/callback?state=1"},
+		"openid.response_nonce": {time.Now().UTC().Format("2006-01-02T15:04:05Z") + "abc"},
+		"openid.assoc_handle":   {"1234567890"},
+		"openid.signed":         {"signed,op_endpoint,claimed_id,identity,return_to,response_nonce,assoc_handle"},
+		"openid.sig":            {"sig"},
+	}
+	sid, err := o.Verify(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sid.RenderSteamID64() != 76561199181487706 {
+		t.Fatalf("unexpected steamid: %d", sid.RenderSteamID64())
+	}
+	if _, err = o.Verify(context.Background(), query); err == nil {
+		t.Fatal("expected replayed nonce to be rejected")
+	}
+
+	query.Set("openid.response_nonce", time.Now().UTC().Format("2006-01-02T15:04:05Z")+"def")
+	query.Set("openid.sig", "forged")
+	if _, err = o.Verify(context.Background(), query); err == nil {
+		t.Fatal("expected forged signature to be rejected")
+	}
+	query.Set("openid.response_nonce", time.Now().UTC().Format("2006-01-02T15:04:05Z")+"ghi")
+	query.Set("openid.sig", "sig")
+	query.Set("openid.return_to", "https://evil.example.com/auth/steam/callback")
+	if _, err = o.Verify(context.Background(), query); err == nil {
+		t.Fatal("expected foreign return_to to be rejected")
+	}
+}
@@ -0,0 +1,289 @@
+// Package steamstore Steam 商店接口(store.steampowered.com/api)，无需 Web API 密钥。
+package steamstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bang-go/network/httpx"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HostStore 商店地址
+	HostStore         = "https://store.steampowered.com"
+	DefaultReqTimeout = 10 * time.Second
+)
+
+// 常用的 appdetails filters，同时查询多个应用时 Steam 只支持 FilterPriceOverview
+const (
+	FilterBasic         = "basic"
+	FilterPriceOverview = "price_overview"
+	FilterPlatforms     = "platforms"
+	FilterCategories    = "categories"
+	FilterGenres        = "genres"
+	FilterReleaseDate   = "release_date"
+)
+
+type Options struct {
+	CountryCode string   //cc，影响价格货币，如 us、cn
+	Language    string   //l，如 english、schinese
+	Filters     []string //filters，仅 appdetails 支持
+}
+
+type PriceOverview struct {
+	Currency         string `json:"currency"`
+	Initial          int    `json:"initial"` //原价，单位为货币的最小单位(如美分)
+	Final            int    `json:"final"`   //折后价
+	DiscountPercent  int    `json:"discount_percent"`
+	InitialFormatted string `json:"initial_formatted"`
+	FinalFormatted   string `json:"final_formatted"`
+}
+
+type Platforms struct {
+	Windows bool `json:"windows"`
+	Mac     bool `json:"mac"`
+	Linux   bool `json:"linux"`
+}
+
+type Category struct {
+	Id          int    `json:"id"`
+	Description string `json:"description"`
+}
+
+type Genre struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+}
+
+type ReleaseDate struct {
+	ComingSoon bool   `json:"coming_soon"`
+	Date       string `json:"date"` //本地化的日期，如 "10 Oct, 2007"
+}
+
+// Requirements 配置需求(html)，没有时 Steam 返回空数组
+type Requirements struct {
+	Minimum     string `json:"minimum"`
+	Recommended string `json:"recommended"`
+}
+
+func (r *Requirements) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		*r = Requirements{}
+		return nil
+	}
+	type requirements Requirements
+	return json.Unmarshal(data, (*requirements)(r))
+}
+
+type Metacritic struct {
+	Score int    `json:"score"`
+	Url   string `json:"url"`
+}
+
+type AppData struct {
+	Type                string         `json:"type"` //game、dlc、demo 等
+	Name                string         `json:"name"`
+	SteamAppId          int            `json:"steam_appid"`
+	RequiredAge         json.Number    `json:"required_age"` //Steam 可能返回数字或字符串
+	IsFree              bool           `json:"is_free"`
+	Dlc                 []int          `json:"dlc"`
+	DetailedDescription string         `json:"detailed_description"`
+	AboutTheGame        string         `json:"about_the_game"`
+	ShortDescription    string         `json:"short_description"`
+	SupportedLanguages  string         `json:"supported_languages"`
+	HeaderImage         string         `json:"header_image"`
+	Website             string         `json:"website"`
+	PcRequirements      Requirements   `json:"pc_requirements"`
+	MacRequirements     Requirements   `json:"mac_requirements"`
+	LinuxRequirements   Requirements   `json:"linux_requirements"`
+	Developers          []string       `json:"developers"`
+	Publishers          []string       `json:"publishers"`
+	PriceOverview       *PriceOverview `json:"price_overview"` //免费应用没有价格
+	Packages            []int          `json:"packages"`
+	Platforms           Platforms      `json:"platforms"`
+	Metacritic          *Metacritic    `json:"metacritic"`
+	Categories          []Category     `json:"categories"`
+	Genres              []Genre        `json:"genres"`
+	ReleaseDate         ReleaseDate    `json:"release_date"`
+}
+
+type PackageApp struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type PackagePrice struct {
+	Currency        string `json:"currency"`
+	Initial         int    `json:"initial"`
+	Final           int    `json:"final"`
+	DiscountPercent int    `json:"discount_percent"`
+	Individual      int    `json:"individual"` //包内应用单独购买的总价
+}
+
+type PackageData struct {
+	Name        string        `json:"name"`
+	PageContent string        `json:"page_content"`
+	PageImage   string        `json:"page_image"`
+	HeaderImage string        `json:"header_image"`
+	SmallLogo   string        `json:"small_logo"`
+	Apps        []PackageApp  `json:"apps"`
+	Price       *PackagePrice `json:"price"`
+	Platforms   Platforms     `json:"platforms"`
+	ReleaseDate ReleaseDate   `json:"release_date"`
+}
+
+// AppDetails 单个应用的查询结果，应用不存在或在当前地区不可用时 Success 为 false
+type AppDetails struct {
+	Success bool
+	Data    *AppData
+}
+
+// PackageDetails 单个礼包的查询结果，礼包不存在时 Success 为 false
+type PackageDetails struct {
+	Success bool
+	Data    *PackageData
+}
+
+// Steam 在 data 为空时返回空数组
+type detailsRaw struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type Store interface {
+	// AppDetails 查询应用详情，结果以 appid 为键，查询多个应用时 Filters 只能为 FilterPriceOverview
+	AppDetails(ctx context.Context, appIds []int, opts *Options) (map[int]*AppDetails, error)
+	// PackageDetails 查询礼包详情，结果以 packageid 为键
+	PackageDetails(ctx context.Context, packageIds []int, opts *Options) (map[int]*PackageDetails, error)
+}
+
+type Config struct {
+	Host    string //默认 HostStore
+	Timeout time.Duration
+}
+
+type storeEntity struct {
+	*Config
+	httpClient httpx.Client
+}
+
+func New(cfg *Config) Store {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultReqTimeout
+	}
+	if cfg.Host == "" {
+		cfg.Host = HostStore
+	}
+	return &storeEntity{Config: cfg, httpClient: httpx.New(&httpx.Config{Timeout: cfg.Timeout})}
+}
+
+func (s *storeEntity) AppDetails(ctx context.Context, appIds []int, opts *Options) (result map[int]*AppDetails, err error) {
+	raw, err := s.get(ctx, "/api/appdetails", "appids", appIds, opts, true)
+	if err != nil {
+		return
+	}
+	result = make(map[int]*AppDetails, len(raw))
+	for id, r := range raw {
+		details := &AppDetails{Success: r.Success}
+		if r.Success {
+			details.Data = &AppData{}
+			if err = decodeData(r.Data, details.Data); err != nil {
+				err = fmt.Errorf("解析应用详情失败, appid:%d, %w", id, err)
+				return
+			}
+		}
+		result[id] = details
+	}
+	return
+}
+
+func (s *storeEntity) PackageDetails(ctx context.Context, packageIds []int, opts *Options) (result map[int]*PackageDetails, err error) {
+	raw, err := s.get(ctx, "/api/packagedetails", "packageids", packageIds, opts, false)
+	if err != nil {
+		return
+	}
+	result = make(map[int]*PackageDetails, len(raw))
+	for id, r := range raw {
+		details := &PackageDetails{Success: r.Success}
+		if r.Success {
+			details.Data = &PackageData{}
+			if err = decodeData(r.Data, details.Data); err != nil {
+				err = fmt.Errorf("解析礼包详情失败, packageid:%d, %w", id, err)
+				return
+			}
+		}
+		result[id] = details
+	}
+	return
+}
+
+func (s *storeEntity) get(ctx context.Context, path string, idsName string, ids []int, opts *Options, withFilters bool) (result map[int]detailsRaw, err error) {
+	if len(ids) == 0 {
+		err = fmt.Errorf("%s不能为空", idsName)
+		return
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	//Steam 对这种请求返回 null，不发送请求直接返回错误
+	if withFilters && len(ids) > 1 && (len(opts.Filters) != 1 || opts.Filters[0] != FilterPriceOverview) {
+		err = fmt.Errorf("同时查询多个%s时 filters 只能为 %s", idsName, FilterPriceOverview)
+		return
+	}
+	strIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strIds = append(strIds, strconv.Itoa(id))
+	}
+	params := map[string]string{idsName: strings.Join(strIds, ",")}
+	if opts.CountryCode != "" {
+		params["cc"] = opts.CountryCode
+	}
+	if opts.Language != "" {
+		params["l"] = opts.Language
+	}
+	if withFilters && len(opts.Filters) > 0 {
+		params["filters"] = strings.Join(opts.Filters, ",")
+	}
+	httpResp, err := s.httpClient.Send(ctx, &httpx.Request{
+		Method:      http.MethodGet,
+		Url:         strings.TrimSuffix(s.Host, "/") + path,
+		Params:      params,
+		ContentType: httpx.ContentForm,
+	})
+	if err != nil {
+		return
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("状态码异常，status: %d", httpResp.StatusCode)
+		return
+	}
+	var raw map[string]detailsRaw
+	if err = json.Unmarshal(httpResp.Content, &raw); err != nil {
+		return
+	}
+	if raw == nil {
+		err = fmt.Errorf("响应内容为空, %s:%s", idsName, params[idsName])
+		return
+	}
+	result = make(map[int]detailsRaw, len(raw))
+	for key, value := range raw {
+		var id int
+		if id, err = strconv.Atoi(key); err != nil {
+			return
+		}
+		result[id] = value
+	}
+	return
+}
+
+func decodeData(data json.RawMessage, out any) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] == '[' {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
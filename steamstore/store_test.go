@@ -0,0 +1,89 @@
+package steamstore_test
+
+import (
+	"context"
+	"github.com/bang-go/steam/steamstore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/appdetails":
+			if q.Get("appids") == "440,1" {
+				if q.Get("filters") != "price_overview" {
+					t.Errorf("unexpected query: %s", r.URL.RawQuery)
+				}
+				_, _ = w.Write([]byte(`{"440":{"success":true,"data":{"price_overview":{"currency":"USD","initial":999,"final":499,"discount_percent":50}}},"1":{"success":false}}`))
+				return
+			}
+			if q.Get("appids") != "440" || q.Get("cc") != "us" || q.Get("l") != "english" || q.Get("filters") != "basic,price_overview" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"440":{"success":true,"data":{"type":"game","name":"Team Fortress 2","steam_appid":440,"required_age":"0","is_free":true,
+"pc_requirements":{"minimum":"<strong>Minimum:</strong>"},"mac_requirements":[],"categories":[{"id":1,"description":"Multi-player"}],
+"genres":[{"id":"1","description":"Action"}],"platforms":{"windows":true,"mac":false,"linux":true},"release_date":{"coming_soon":false,"date":"10 Oct, 2007"}}}}`))
+		case "/api/packagedetails":
+			if q.Get("packageids") == "469,470" {
+				//Steam 不支持的请求返回 null
+				_, _ = w.Write([]byte(`null`))
+				return
+			}
+			if q.Get("packageids") != "469" || q.Has("filters") {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"469":{"success":true,"data":{"name":"The Orange Box","apps":[{"id":440,"name":"Team Fortress 2"}],
+"price":{"currency":"USD","initial":1999,"final":999,"discount_percent":50,"individual":5995}}}}`))
+		}
+	}))
+	defer srv.Close()
+	store := steamstore.New(&steamstore.Config{Host: srv.URL})
+	ctx := context.Background()
+
+	apps, err := store.AppDetails(ctx, []int{440}, &steamstore.Options{
+		CountryCode: "us",
+		Language:    "english",
+		Filters:     []string{steamstore.FilterBasic, steamstore.FilterPriceOverview},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf2 := apps[440].Data
+	if tf2 == nil || tf2.Name != "Team Fortress 2" || !tf2.IsFree || tf2.PriceOverview != nil {
+		t.Fatalf("unexpected app 440: %+v", tf2)
+	}
+	if tf2.PcRequirements.Minimum == "" || tf2.MacRequirements.Minimum != "" || tf2.Genres[0].Id != "1" || tf2.Categories[0].Id != 1 {
+		t.Fatalf("unexpected app 440: %+v", tf2)
+	}
+	if !tf2.Platforms.Linux || tf2.ReleaseDate.Date != "10 Oct, 2007" {
+		t.Fatalf("unexpected app 440: %+v", tf2)
+	}
+
+	//同时查询多个应用时只支持 price_overview
+	if _, err = store.AppDetails(ctx, []int{440, 1}, &steamstore.Options{Filters: []string{steamstore.FilterBasic}}); err == nil {
+		t.Fatal("expected error for unsupported filters")
+	}
+	if apps, err = store.AppDetails(ctx, []int{440, 1}, &steamstore.Options{Filters: []string{steamstore.FilterPriceOverview}}); err != nil {
+		t.Fatal(err)
+	}
+	if apps[1] == nil || apps[1].Success || apps[1].Data != nil {
+		t.Fatalf("unexpected app 1: %+v", apps[1])
+	}
+	if price := apps[440].Data.PriceOverview; price == nil || price.Final != 499 {
+		t.Fatalf("unexpected app 440: %+v", apps[440].Data)
+	}
+
+	if _, err = store.PackageDetails(ctx, []int{469, 470}, nil); err == nil {
+		t.Fatal("expected error for null response")
+	}
+	packages, err := store.PackageDetails(ctx, []int{469}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := packages[469].Data; p == nil || p.Price.Final != 999 || p.Apps[0].Id != 440 {
+		t.Fatalf("unexpected package: %+v", packages[469])
+	}
+}